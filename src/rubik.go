@@ -1,12 +1,128 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
 	"rubik"
+	"runtime"
+	"strings"
+	"time"
 )
 
+// Usage:
+//
+//	rubik                  show a few cubes and moves
+//	rubik pdb [-dir DIR]   build Korf's pattern databases into DIR
+//	rubik solve [-solver NAMES] [-metric METRIC] [-moves MOVES] [-pdb DIR] [-timeout DURATION] [-workers N] SCRAMBLE
+//	                       solve the cube obtained with the scramble, with each solver,
+//	                       IDA* using the pattern databases of DIR if given
 func main() {
-	cube := rubik.NewCube("sssssssssqqqqqqqqqsssssssssqqqqqqqqqsssssssssqqqqqqqqq")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "pdb":
+			buildPatternDatabases(os.Args[2:])
+			return
+		case "solve":
+			solve(os.Args[2:])
+			return
+		}
+	}
+	demo()
+}
+
+// Solve a scrambled cube with several solvers, and compare their solutions.
+func solve(args []string) {
+	flags := flag.NewFlagSet("solve", flag.ExitOnError)
+	names := flags.String("solver", "", "comma-separated solvers among "+strings.Join(rubik.SolverNames(), ", ")+
+		" (default: all the solvers supporting the metric)")
+	metricName := flags.String("metric", "HTM", "metric the solutions are optimized in")
+	allowed := flags.String("moves", "", "layers the solutions may turn, such as \"R U\" (default: all of them)")
+	pdbDir := flags.String("pdb", "", "directory of the pattern databases built by the pdb command, used by IDA*")
+	timeout := flags.Duration("timeout", time.Minute, "time given to each solver")
+	workers := flags.Int("workers", runtime.NumCPU(), "goroutines used by parallel solvers")
+	flags.Parse(args)
+
+	scramble, err := rubik.ParseAlgorithm(strings.Join(flags.Args(), " "))
+	if err != nil {
+		fail(err)
+	}
+	opts := rubik.Options{Metric: -1}
+	for _, metric := range rubik.Metrics {
+		if strings.EqualFold(metric.String(), *metricName) {
+			opts.Metric = metric
+		}
+	}
+	if opts.Metric < 0 {
+		fail(fmt.Errorf("unknown metric %q", *metricName))
+	}
+	if *allowed != "" {
+		moves, err := rubik.ParseAlgorithm(*allowed)
+		if err != nil {
+			fail(err)
+		}
+		opts.Moves = moves
+	}
+	if *pdbDir != "" {
+		dbs, err := rubik.LoadKorfPatternDatabases(*pdbDir)
+		if err != nil {
+			fail(err)
+		}
+		defer dbs.Close()
+		opts.Heuristic = dbs
+	}
+	var selected []string
+	if *names != "" {
+		selected = strings.Split(*names, ",")
+	}
+
+	cube := scramble.Apply(rubik.NewSolvedCube())
+	opts.Timeout = *timeout
+	opts.Workers = *workers
+	results, err := rubik.CompareSolvers(context.Background(), cube, opts, selected...)
+	if err != nil {
+		fail(err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Printf("%-16s %-10s %s\n", result.Solver, result.Duration.Round(time.Millisecond), result.Err)
+		} else {
+			fmt.Printf("%-16s %-10s %2d %s\n", result.Solver, result.Duration.Round(time.Millisecond),
+				opts.Metric.Length(result.Solution), result.Solution)
+		}
+	}
+}
+
+// Print the error and exit.
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// Build Korf's pattern databases, and write them into a directory.
+func buildPatternDatabases(args []string) {
+	flags := flag.NewFlagSet("pdb", flag.ExitOnError)
+	dir := flags.String("dir", ".", "directory to write the pattern databases into")
+	flags.Parse(args)
+
+	for _, file := range rubik.KorfPatternFiles {
+		start := time.Now()
+		db, err := rubik.NewPatternDatabase(file.Pattern)
+		if err == nil {
+			err = db.Save(filepath.Join(*dir, file.Name))
+		}
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", file.Name, err))
+		}
+		fmt.Printf("%s: %d entries in %s\n", file.Name, file.Pattern.Size(), time.Since(start).Round(time.Second))
+	}
+}
+
+// Show a few cubes and moves.
+func demo() {
+	cube := rubik.MustParseCube("sssssssssqqqqqqqqqsssssssssqqqqqqqqqsssssssssqqqqqqqqq")
 	fmt.Printf("Cube:\n%s %v\n", cube, cube.IsSolved())
 
 	solved := rubik.NewSolvedCube()
@@ -27,10 +143,3 @@ func main() {
 	d := solved.D()
 	fmt.Printf("o D:\n%s\n", d)
 }
-
-// Cannot be solved by this program. Probably about 5 moves to solution.
-// func TestSolveFunnyLooking(t *testing.T) {
-// 	origin := NewCube("ggggwgggg rrrrgrrrr wwwwrwwww ooooboooo yyyyoyyyy bbbbybbbb")
-// 	moves := Solve(origin)
-// 	fmt.Printf("%s -> %s\n", origin, moves)
-// }
@@ -0,0 +1,544 @@
+package rubik
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Algorithms, aka sequences of moves, and their notation.
+//
+// ParseAlgorithm reads the notation commonly used by cubers, for instance:
+//
+//	[R U R', D] (R U)3 R2' x // Comment until the end of the line
+//
+// Spaces between moves are optional. Primes may be written ', ’, ′ or `. A move may be
+// followed by a number of quarter turns, so that "R2'" or "R3" are the same as "R2" and "R'".
+// A sequence between parentheses or brackets may be followed by a repeat count.
+// [A, B] is the commutator A B A' B', and [A: B] the conjugate A B A'. Once groups are
+// expanded, an algorithm may not be longer than MAX_ALGORITHM_LENGTH moves.
+
+// Longest algorithm ParseAlgorithm expands repeats, commutators and conjugates to.
+const MAX_ALGORITHM_LENGTH = 100000
+
+// Sequence of moves.
+type Algorithm []Move
+
+// String representation, with moves separated by spaces.
+func (alg Algorithm) String() string {
+	s := make([]string, len(alg))
+	for i, move := range alg {
+		s[i] = string(move)
+	}
+	return strings.Join(s, " ")
+}
+
+// Error raised when parsing an algorithm, telling where the problem lies in the input.
+type SyntaxError struct {
+	Input  string
+	Offset int // Byte offset in the input
+	Line   int // Line number, starting at 1
+	Column int // Column number in characters, starting at 1
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at line %d, column %d: %s", ErrSyntax, e.Line, e.Column, e.Msg)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return ErrSyntax
+}
+
+// Parse an algorithm written in the usual notation, and expand it into a sequence of moves.
+//
+// Return a *SyntaxError telling where the input could not be parsed.
+func ParseAlgorithm(s string) (Algorithm, error) {
+	p := &algorithmParser{input: s}
+	alg, err := p.sequence("")
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.input) {
+		r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+		return nil, p.error(p.pos, fmt.Sprintf("unexpected %q", r))
+	}
+	return alg, nil
+}
+
+// Like ParseAlgorithm, but panic if the algorithm cannot be parsed.
+func MustParseAlgorithm(s string) Algorithm {
+	alg, err := ParseAlgorithm(s)
+	if err != nil {
+		panic(err)
+	}
+	return alg
+}
+
+// Recursive descent parser for the algorithm notation.
+type algorithmParser struct {
+	input string
+	pos   int
+}
+
+// Characters that may be used as primes.
+const primes = "'’′`"
+
+// Build a SyntaxError at the given byte offset.
+func (p *algorithmParser) error(offset int, msg string) *SyntaxError {
+	line, column := 1, 1
+	for _, r := range p.input[:offset] {
+		if r == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+	return &SyntaxError{p.input, offset, line, column, msg}
+}
+
+// Skip spaces and comments.
+func (p *algorithmParser) skip() {
+	for p.pos < len(p.input) {
+		switch {
+		case strings.IndexByte(" \t\r\n", p.input[p.pos]) >= 0:
+			p.pos++
+		case strings.HasPrefix(p.input[p.pos:], "//"):
+			end := strings.IndexByte(p.input[p.pos:], '\n')
+			if end < 0 {
+				p.pos = len(p.input)
+			} else {
+				p.pos += end
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Parse moves and groups until the end of the input, or one of the `stop` characters.
+func (p *algorithmParser) sequence(stop string) (Algorithm, error) {
+	alg := Algorithm{}
+	for {
+		p.skip()
+		if p.pos >= len(p.input) || strings.IndexByte(stop, p.input[p.pos]) >= 0 {
+			return alg, nil
+		}
+		start := p.pos
+		switch c := p.input[p.pos]; {
+		case c == '(':
+			p.pos++
+			group, err := p.sequence(")")
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")", start); err != nil {
+				return nil, err
+			}
+			if group, err = p.repeat(group); err != nil {
+				return nil, err
+			}
+			if err := p.checkLength(start, len(alg)+len(group)); err != nil {
+				return nil, err
+			}
+			alg = append(alg, group...)
+		case c == '[':
+			group, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			if group, err = p.repeat(group); err != nil {
+				return nil, err
+			}
+			if err := p.checkLength(start, len(alg)+len(group)); err != nil {
+				return nil, err
+			}
+			alg = append(alg, group...)
+		case strings.IndexByte("UDLRFBMESudlrfbxyz", c) >= 0:
+			move, err := p.move()
+			if err != nil {
+				return nil, err
+			}
+			if move != "" {
+				alg = append(alg, move)
+			}
+		default:
+			r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+			return nil, p.error(p.pos, fmt.Sprintf("unexpected %q", r))
+		}
+	}
+}
+
+// Consume one of the expected characters, or fail mentioning the opening character.
+func (p *algorithmParser) expect(expected string, opening int) error {
+	if p.pos < len(p.input) && strings.IndexByte(expected, p.input[p.pos]) >= 0 {
+		p.pos++
+		return nil
+	}
+	if p.pos >= len(p.input) {
+		return p.error(opening, fmt.Sprintf("%q is never closed", p.input[opening]))
+	}
+	r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+	return p.error(p.pos, fmt.Sprintf("unexpected %q, expecting one of %q", r, expected))
+}
+
+// Parse a commutator [A, B] or a conjugate [A: B].
+func (p *algorithmParser) bracket() (Algorithm, error) {
+	start := p.pos
+	p.pos++
+	a, err := p.sequence(",:]")
+	if err != nil {
+		return nil, err
+	}
+	separator := p.pos
+	if err := p.expect(",:", start); err != nil {
+		return nil, err
+	}
+	b, err := p.sequence("]")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("]", start); err != nil {
+		return nil, err
+	}
+	if err := p.checkLength(start, 2*(len(a)+len(b))); err != nil {
+		return nil, err
+	}
+
+	expanded := append(Algorithm{}, a...)
+	expanded = append(expanded, b...)
+	expanded = append(expanded, a.Inverse()...)
+	if p.input[separator] == ',' {
+		expanded = append(expanded, b.Inverse()...)
+	}
+	return expanded, nil
+}
+
+// Parse an optional repeat count following a group, and repeat the group accordingly.
+func (p *algorithmParser) repeat(group Algorithm) (Algorithm, error) {
+	end := p.pos
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		p.pos = end
+		return group, nil
+	}
+	count, err := strconv.Atoi(p.input[start:p.pos])
+	if err != nil || count > 1000 {
+		return nil, p.error(start, fmt.Sprintf("invalid repeat count %q", p.input[start:p.pos]))
+	}
+	if err := p.checkLength(start, count*len(group)); err != nil {
+		return nil, err
+	}
+	repeated := Algorithm{}
+	for i := 0; i < count; i++ {
+		repeated = append(repeated, group...)
+	}
+	return repeated, nil
+}
+
+// Fail at the given offset if an expansion of `length` moves would be too long. Groups are
+// expanded from the innermost, so checking each expansion bounds the whole algorithm.
+func (p *algorithmParser) checkLength(offset, length int) error {
+	if length > MAX_ALGORITHM_LENGTH {
+		return p.error(offset, fmt.Sprintf("algorithm longer than %d moves", MAX_ALGORITHM_LENGTH))
+	}
+	return nil
+}
+
+// Parse a single move with its optional amount and prime.
+// Return an empty move when the amount cancels the move, as in "R4".
+func (p *algorithmParser) move() (Move, error) {
+	start := p.pos
+	base := p.input[p.pos : p.pos+1]
+	p.pos++
+	if strings.IndexByte("udlrfb", base[0]) >= 0 {
+		base = strings.ToUpper(base) + "w"
+	} else if strings.IndexByte("UDLRFB", base[0]) >= 0 && p.pos < len(p.input) && p.input[p.pos] == 'w' {
+		base += "w"
+		p.pos++
+	}
+
+	digits := p.digits()
+	prime := p.prime()
+	if prime && digits == "" {
+		digits = p.digits()
+	}
+	amount := 1
+	if digits != "" {
+		amount, _ = strconv.Atoi(digits)
+	}
+	if prime {
+		amount = -amount
+	}
+
+	var move Move
+	switch (amount%4 + 4) % 4 {
+	case 0:
+		return "", nil
+	case 1:
+		move = Move(base)
+	case 2:
+		move = Move(base + "2")
+	case 3:
+		move = Move(base + "'")
+	}
+	if _, found := movePermutations[move]; !found {
+		return "", p.error(start, fmt.Sprintf("unknown move %q", p.input[start:p.pos]))
+	}
+	return move, nil
+}
+
+// Consume a number of at most 3 digits, and return it.
+func (p *algorithmParser) digits() string {
+	start := p.pos
+	for p.pos < len(p.input) && p.pos-start < 3 && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// Consume a prime, and return `true` if there was one.
+func (p *algorithmParser) prime() bool {
+	r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+	if p.pos < len(p.input) && strings.ContainsRune(primes, r) {
+		p.pos += size
+		return true
+	}
+	return false
+}
+
+// Return the algorithm undoing this one.
+func (alg Algorithm) Inverse() Algorithm {
+	inverse := make(Algorithm, len(alg))
+	for i, move := range alg {
+		inverse[len(alg)-1-i] = move.Inverse()
+	}
+	return inverse
+}
+
+// Apply this algorithm to the given cube, and return the new Cube.
+// Panic if the algorithm contains an unknown move, which cannot happen with
+// algorithms built by ParseAlgorithm.
+func (alg Algorithm) Apply(cube Cube) Cube {
+	turned, err := alg.TryApply(cube)
+	if err != nil {
+		panic(err)
+	}
+	return turned
+}
+
+// Like Apply, but return an error if the algorithm contains an unknown move.
+func (alg Algorithm) TryApply(cube Cube) (Cube, error) {
+	for _, move := range alg {
+		var err error
+		if cube, err = cube.TryTurn(move); err != nil {
+			return cube, err
+		}
+	}
+	return cube, nil
+}
+
+// Check if both algorithms have the same effect on any cube,
+// even if they are written differently.
+func (alg Algorithm) EquivalentTo(other Algorithm) bool {
+	a, errA := alg.TryApply(labeledCube())
+	b, errB := other.TryApply(labeledCube())
+	return errA == nil && errB == nil && a == b
+}
+
+// Return a cube where each facelet is labeled with its own index,
+// so that the effect of moves on every single facelet can be told apart.
+func labeledCube() Cube {
+	var cube Cube
+	for i := range cube {
+		cube[i] = byte(i)
+	}
+	return cube
+}
+
+// Axis of the cube, used to mirror algorithms.
+type Axis int
+
+const (
+	X_AXIS Axis = iota // Through L and R
+	Y_AXIS             // Through U and D
+	Z_AXIS             // Through F and B
+)
+
+// Facelet permutation of the mirror image through the plane perpendicular to each axis.
+var mirrors [3]Permutation
+
+func init() {
+	at := func(face, row, col int) uint8 {
+		return uint8(face*9 + row*3 + col)
+	}
+	for face := 0; face < 6; face++ {
+		for row := 0; row < 3; row++ {
+			for col := 0; col < 3; col++ {
+				i := face*9 + row*3 + col
+
+				// Exchange L and R
+				switch face {
+				case 2:
+					mirrors[X_AXIS][i] = at(4, row, 2-col)
+				case 4:
+					mirrors[X_AXIS][i] = at(2, row, 2-col)
+				default:
+					mirrors[X_AXIS][i] = at(face, row, 2-col)
+				}
+
+				// Exchange U and D
+				switch face {
+				case 0:
+					mirrors[Y_AXIS][i] = at(5, 2-row, col)
+				case 5:
+					mirrors[Y_AXIS][i] = at(0, 2-row, col)
+				default:
+					mirrors[Y_AXIS][i] = at(face, 2-row, col)
+				}
+
+				// Exchange F and B
+				switch face {
+				case 1:
+					mirrors[Z_AXIS][i] = at(3, row, 2-col)
+				case 3:
+					mirrors[Z_AXIS][i] = at(1, row, 2-col)
+				case 0, 5:
+					mirrors[Z_AXIS][i] = at(face, 2-row, col)
+				default:
+					mirrors[Z_AXIS][i] = at(face, row, 2-col)
+				}
+			}
+		}
+	}
+}
+
+// Return the move whose facelet permutation is `inverse(p) then move then p`.
+func conjugateMove(move Move, p Permutation) Move {
+	m, found := movePermutations[move]
+	if !found {
+		return move
+	}
+	conjugate := p.Inverse().Then(*m).Then(p)
+	for _, candidate := range AllMoves {
+		if *movePermutations[candidate] == conjugate {
+			return candidate
+		}
+	}
+	panic("unreachable statement reached")
+}
+
+// Return the mirror image of this algorithm through the plane perpendicular to the
+// given axis. For instance, mirroring "R U R' U'" through X_AXIS gives "L' U' L U".
+func (alg Algorithm) Mirror(axis Axis) Algorithm {
+	mirrored := make(Algorithm, len(alg))
+	for i, move := range alg {
+		mirrored[i] = conjugateMove(move, mirrors[axis])
+	}
+	return mirrored
+}
+
+// Return the algorithm having the same effect as this one, once the cube has been rotated
+// with the given rotation. For instance, rotating "R U" with "y" gives "F U".
+func (alg Algorithm) Rotate(rotation Move) (Algorithm, error) {
+	if !isRotation(rotation) {
+		return nil, &ParseError{string(rotation), ErrUnknownMove}
+	}
+	p, found := movePermutations[rotation]
+	if !found {
+		return nil, &ParseError{string(rotation), ErrUnknownMove}
+	}
+	rotated := make(Algorithm, len(alg))
+	for i, move := range alg {
+		rotated[i] = conjugateMove(move, *p)
+	}
+	return rotated, nil
+}
+
+// Return a shorter algorithm with the same effect: consecutive moves turning the same
+// layer are merged or cancelled, such as "R R" into "R2" or "R R'" into nothing, and so are
+// moves separated by commuting moves around the same axis, such as "R L R" into "R2 L".
+func (alg Algorithm) Simplify() Algorithm {
+	type turn struct {
+		layer  string
+		amount int
+	}
+	type group struct {
+		axis  byte
+		turns []turn
+	}
+
+	groups := []*group{}
+	for _, move := range alg {
+		layer, amount := splitMove(move)
+		axis := axisOf(layer)
+		if len(groups) == 0 || groups[len(groups)-1].axis != axis {
+			groups = append(groups, &group{axis: axis})
+		}
+		top := groups[len(groups)-1]
+
+		merged := false
+		for i := range top.turns {
+			if top.turns[i].layer == layer {
+				top.turns[i].amount = (top.turns[i].amount + amount) % 4
+				if top.turns[i].amount == 0 {
+					top.turns = append(top.turns[:i], top.turns[i+1:]...)
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			top.turns = append(top.turns, turn{layer, amount})
+		}
+		if len(top.turns) == 0 {
+			groups = groups[:len(groups)-1]
+		}
+	}
+
+	simplified := Algorithm{}
+	for _, g := range groups {
+		for _, t := range g.turns {
+			simplified = append(simplified, Move(t.layer+[]string{"", "", "2", "'"}[t.amount]))
+		}
+	}
+	return simplified
+}
+
+// Split a move into the layer it turns, such as "R", "M", "Rw" or "x",
+// and its number of clockwise quarter turns.
+func splitMove(move Move) (string, int) {
+	s := string(move)
+	switch {
+	case strings.HasSuffix(s, "'"):
+		return s[:len(s)-1], 3
+	case strings.HasSuffix(s, "2"):
+		return s[:len(s)-1], 2
+	default:
+		return s, 1
+	}
+}
+
+// Axis a layer turns around, as the letter of the matching rotation.
+func axisOf(layer string) byte {
+	if layer == "" {
+		return 0
+	}
+	switch layer[0] {
+	case 'R', 'L', 'M', 'x':
+		return 'x'
+	case 'U', 'D', 'E', 'y':
+		return 'y'
+	case 'F', 'B', 'S', 'z':
+		return 'z'
+	default:
+		return layer[0]
+	}
+}
@@ -0,0 +1,212 @@
+package rubik
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseAlgorithm(t *testing.T) {
+	testCases := []struct {
+		Input    string
+		Expected string
+	}{
+		{"", ""},
+		{"R U R' U'", "R U R' U'"},
+		{"RUR'U'", "R U R' U'"},
+		{"  R  U\tR'\nU' ", "R U R' U'"},
+		{"R’ U′ F` B'", "R' U' F' B'"},
+		{"R2 R2' R'2 R3 R4 R5", "R2 R2 R2 R' R"},
+		{"M E' S2 x y' z2", "M E' S2 x y' z2"},
+		{"Rw r' u2 Fw'", "Rw Rw' Uw2 Fw'"},
+		{"(R U)3", "R U R U R U"},
+		{"(R U) 2 D", "R U R U D"},
+		{"(R (U)2)2", "R U U R U U"},
+		{"[R, U]", "R U R' U'"},
+		{"[R U R', D]", "R U R' D R U' R' D'"},
+		{"[R: U]", "R U R'"},
+		{"[F: [R, U]]", "F R U R' U' F'"},
+		{"[R, U]2", "R U R' U' R U R' U'"},
+		{"[R U R', D] (R U)3 R2' x", "R U R' D R U' R' D' R U R U R U R2 x"},
+		{"R U // sexy move\nR' U' // again", "R U R' U'"},
+	}
+	for _, tc := range testCases {
+		alg, err := ParseAlgorithm(tc.Input)
+		if err != nil {
+			t.Errorf("Parse %q failed: %s", tc.Input, err)
+			continue
+		}
+		if alg.String() != tc.Expected {
+			t.Errorf("Parse %q incorrect:\nGot  %s\nWant %s", tc.Input, alg, tc.Expected)
+		}
+	}
+}
+
+func TestParseAlgorithmErrors(t *testing.T) {
+	testCases := []struct {
+		Input        string
+		Offset       int
+		Line, Column int
+	}{
+		{"R U Q", 4, 1, 5},
+		{"R U)", 3, 1, 4},
+		{"(R U", 0, 1, 1},
+		{"[R U]", 4, 1, 5},
+		{"[R, U", 0, 1, 1},
+		{"R, U", 1, 1, 2},
+		{"R U\nR' Xw", 7, 2, 4},
+		{"Mw", 1, 1, 2},
+		{"R’ Q", 5, 1, 4},
+		{"((((R)1000)1000)1000)", 11, 1, 12},
+		{"[(R U)1000, ((U)1000)50]", 0, 1, 1},
+		{strings.Repeat("(R U)1000 ", 51), 500, 1, 501},
+	}
+	for _, tc := range testCases {
+		_, err := ParseAlgorithm(tc.Input)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || !errors.Is(err, ErrSyntax) {
+			t.Errorf("Parse %q should fail with a syntax error, got %v", tc.Input, err)
+			continue
+		}
+		if syntaxErr.Offset != tc.Offset || syntaxErr.Line != tc.Line || syntaxErr.Column != tc.Column {
+			t.Errorf("Parse %q error position incorrect:\nGot  %d (%d:%d) %s\nWant %d (%d:%d)",
+				tc.Input, syntaxErr.Offset, syntaxErr.Line, syntaxErr.Column, err, tc.Offset, tc.Line, tc.Column)
+		}
+	}
+}
+
+func TestMoveInverse(t *testing.T) {
+	for _, move := range AllMoves {
+		origin := NewSolvedCube().F().R()
+		if !origin.MustTurn(move).MustTurn(move.Inverse()).Equals(origin) {
+			t.Errorf("o %s %s should return to o", move, move.Inverse())
+		}
+	}
+}
+
+func TestAlgorithmInverse(t *testing.T) {
+	alg := MustParseAlgorithm("R U2 M' x Fw")
+	if got := alg.Inverse().String(); got != "Fw' x' M U2 R'" {
+		t.Errorf("Inverse incorrect:\nGot  %s\nWant %s", got, "Fw' x' M U2 R'")
+	}
+	origin := NewSolvedCube()
+	if back := alg.Inverse().Apply(alg.Apply(origin)); !back.Equals(origin) {
+		t.Errorf("Algorithm then inverse should return to o:\nGot  %s", back)
+	}
+}
+
+func TestAlgorithmApply(t *testing.T) {
+	turned := MustParseAlgorithm("F R2 U L").Apply(NewSolvedCube())
+	target := MustParseCube("bwwbwwyyr orwogbygb gbbrrwrrw ooygbygbr oogoogyyb rrwgywgyo")
+	if !turned.Equals(target) {
+		t.Errorf("Apply incorrect:\nGot  %s\nWant %s", turned, target)
+	}
+	if _, err := (Algorithm{"Q"}).TryApply(NewSolvedCube()); !errors.Is(err, ErrUnknownMove) {
+		t.Errorf("Apply should fail.\nGot  %v\nWant %s", err, ErrUnknownMove)
+	}
+}
+
+func TestAlgorithmEquivalentTo(t *testing.T) {
+	testCases := []struct {
+		A, B       string
+		Equivalent bool
+	}{
+		{"R R", "R2", true},
+		{"R L", "L R", true},
+		{"R U", "U R", false},
+		{"M", "R L' x'", true},
+		{"(R U R' U')6", "", true},
+		{"x", "", false},
+		{"Rw", "L x", true},
+	}
+	for _, tc := range testCases {
+		a, b := MustParseAlgorithm(tc.A), MustParseAlgorithm(tc.B)
+		if a.EquivalentTo(b) != tc.Equivalent {
+			t.Errorf("%q equivalent to %q should be %v", tc.A, tc.B, tc.Equivalent)
+		}
+	}
+}
+
+func TestAlgorithmMirror(t *testing.T) {
+	testCases := []struct {
+		Alg      string
+		Axis     Axis
+		Expected string
+	}{
+		{"R U R' U'", X_AXIS, "L' U' L U"},
+		{"M2 U M2 U2 M2 U M2", X_AXIS, "M2 U' M2 U2 M2 U' M2"},
+		{"R U R' U'", Y_AXIS, "R' D' R D"},
+		{"F R x y Rw", Z_AXIS, "B' R' x' y' Rw'"},
+	}
+	for _, tc := range testCases {
+		got := MustParseAlgorithm(tc.Alg).Mirror(tc.Axis)
+		if got.String() != tc.Expected {
+			t.Errorf("Mirror of %q incorrect:\nGot  %s\nWant %s", tc.Alg, got, tc.Expected)
+		}
+	}
+
+	// Mirroring twice gives back the same algorithm
+	alg := MustParseAlgorithm("R U2 F' M E2 S' Lw x y' z2")
+	for _, axis := range []Axis{X_AXIS, Y_AXIS, Z_AXIS} {
+		if back := alg.Mirror(axis).Mirror(axis); back.String() != alg.String() {
+			t.Errorf("Mirror twice incorrect:\nGot  %s\nWant %s", back, alg)
+		}
+	}
+}
+
+func TestAlgorithmRotate(t *testing.T) {
+	testCases := []struct {
+		Alg      string
+		Rotation Move
+		Expected string
+	}{
+		{"R U", ROTATE_Y, "F U"},
+		{"R U", ROTATE_Y_COUNTER, "B U"},
+		{"F U M", ROTATE_X, "U B M"},
+		{"R U R' U'", ROTATE_Z_HALF, "L D L' D'"},
+	}
+	for _, tc := range testCases {
+		got, err := MustParseAlgorithm(tc.Alg).Rotate(tc.Rotation)
+		if err != nil || got.String() != tc.Expected {
+			t.Errorf("Rotation of %q incorrect:\nGot  %s %v\nWant %s", tc.Alg, got, err, tc.Expected)
+		}
+		// Rotating, applying the rotated algorithm and rotating back is the original algorithm
+		rotation := Algorithm{tc.Rotation}
+		conjugate := append(append(append(Algorithm{}, rotation...), got...), rotation.Inverse()...)
+		if !conjugate.EquivalentTo(MustParseAlgorithm(tc.Alg)) {
+			t.Errorf("Rotation of %q should be equivalent: %s", tc.Alg, conjugate)
+		}
+	}
+	if _, err := MustParseAlgorithm("R").Rotate(RIGHT); !errors.Is(err, ErrUnknownMove) {
+		t.Errorf("Rotation should fail.\nGot  %v\nWant %s", err, ErrUnknownMove)
+	}
+}
+
+func TestAlgorithmSimplify(t *testing.T) {
+	testCases := []struct {
+		Alg      string
+		Expected string
+	}{
+		{"R R'", ""},
+		{"R R", "R2"},
+		{"R2 R", "R'"},
+		{"R R R R", ""},
+		{"R U U' R'", ""},
+		{"R L R", "R2 L"},
+		{"R L R'", "L"},
+		{"U D U' D2 F", "D' F"},
+		{"R M' R Rw", "R2 M' Rw"},
+		{"x x' y y", "y2"},
+		{"R U R' U'", "R U R' U'"},
+	}
+	for _, tc := range testCases {
+		alg := MustParseAlgorithm(tc.Alg)
+		got := alg.Simplify()
+		if got.String() != tc.Expected {
+			t.Errorf("Simplification of %q incorrect:\nGot  %s\nWant %s", tc.Alg, got, tc.Expected)
+		}
+		if !got.EquivalentTo(alg) {
+			t.Errorf("Simplification of %q should be equivalent: %s", tc.Alg, got)
+		}
+	}
+}
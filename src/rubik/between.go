@@ -0,0 +1,131 @@
+package rubik
+
+import (
+	"context"
+	"fmt"
+)
+
+// Solving from one cube to another.
+//
+// Moves bringing a cube `from` to a cube `to` are found by relabeling the stickers of `from`:
+// each sticker gets the color of the face where `to` has it, as if `to` were solved. Moves
+// solving the relabeled cube bring `from` to `to`, so that any solver can be used. Solvers
+// accept solved cubes in any orientation: the rotations turning the cube into the
+// orientation of `to` are added to their solution.
+
+// Relabel the stickers of `from`, so that moves bringing it to `to` solve the relabeled cube.
+// The relabeled cube is colored as NewSolvedCube.
+//
+// Return an ErrInvalidCube (as a *ValidationError) if either cube cannot be reached from
+// a solved cube, or ErrMismatchedCubes if they do not have the same pieces.
+func Relabel(from, to Cube) (Cube, error) {
+	if err := from.Validate(); err != nil {
+		return Cube{}, err
+	}
+	if err := to.Validate(); err != nil {
+		return Cube{}, err
+	}
+	positions := map[sticker]int{}
+	for i := range to {
+		positions[to.sticker(i)] = i
+	}
+	solved := NewSolvedCube()
+	relabeled := Cube{}
+	for i := range from {
+		position, found := positions[from.sticker(i)]
+		if !found {
+			return Cube{}, fmt.Errorf("%w: %s and %s", ErrMismatchedCubes, from, to)
+		}
+		relabeled[i] = solved[position]
+	}
+	return relabeled, nil
+}
+
+// Colors of a piece, starting with one of its facelets then going clockwise,
+// which tells a sticker apart from all others.
+type sticker [3]byte
+
+// Other facelets of the piece of each facelet, going clockwise, or -1.
+var pieceFacelets = [54][2]int{}
+
+func init() {
+	for i := range pieceFacelets {
+		pieceFacelets[i] = [2]int{-1, -1}
+	}
+	for _, facelets := range cornerFacelets {
+		for n, facelet := range facelets {
+			pieceFacelets[facelet] = [2]int{facelets[(n+1)%3], facelets[(n+2)%3]}
+		}
+	}
+	for _, facelets := range edgeFacelets {
+		pieceFacelets[facelets[0]][0] = facelets[1]
+		pieceFacelets[facelets[1]][0] = facelets[0]
+	}
+}
+
+// Sticker found at the given facelet.
+func (cube Cube) sticker(facelet int) sticker {
+	s := sticker{cube[facelet]}
+	for n, other := range pieceFacelets[facelet] {
+		if other >= 0 {
+			s[n+1] = cube[other]
+		}
+	}
+	return s
+}
+
+// Solve the cube `from` into the cube `to` with Kociemba's algorithm, and return a list
+// of moves. See SolveBetweenWith for other solvers.
+//
+// Return an ErrInvalidCube (as a *ValidationError) if either cube cannot be reached from
+// a solved cube, or ErrMismatchedCubes if they do not have the same pieces.
+func SolveBetween(from, to Cube) (Algorithm, error) {
+	return SolveBetweenWith(context.Background(), kociembaSolver, from, to, Options{})
+}
+
+// Like SolveBetween, but with the solver and limits of SolveContext: the solution is optimal
+// in the metric of the options.
+func SolveBetweenContext(ctx context.Context, from, to Cube, opts Options) (Algorithm, error) {
+	return SolveBetweenWith(ctx, defaultSolver(opts.Metric), from, to, opts)
+}
+
+// Solve the cube `from` into the cube `to` with the given solver.
+//
+// Solutions end with the rotations turning the cube into the orientation of `to`, if it
+// cannot be reached otherwise. They only count in the execution turn metric, where solutions
+// are optimal up to these rotations.
+//
+// Return the errors of Relabel and of the solver, or ErrUnsupportedGoal if the options have
+// a goal: the goal is `to`.
+func SolveBetweenWith(ctx context.Context, solver Solver, from, to Cube, opts Options) (Algorithm, error) {
+	if opts.Goal != nil {
+		return nil, fmt.Errorf("%w: the goal is the target cube", ErrUnsupportedGoal)
+	}
+	cube, err := Relabel(from, to)
+	if err != nil {
+		return nil, err
+	}
+	solution, err := solver.Solve(ctx, cube, opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(solution, rotationBetween(solution.Apply(cube), NewSolvedCube())...), nil
+}
+
+// Shortest sequence of rotations turning the cube into the target, or nil if there is none.
+func rotationBetween(cube, target Cube) Algorithm {
+	paths := map[Cube]Algorithm{cube: {}}
+	for queue := []Cube{cube}; len(queue) > 0; queue = queue[1:] {
+		if queue[0] == target {
+			return paths[queue[0]]
+		}
+		for _, rotation := range Rotations {
+			next := queue[0].permute(movePermutations[rotation])
+			if _, found := paths[next]; !found {
+				paths[next] = append(append(Algorithm{}, paths[queue[0]]...), rotation)
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package rubik
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRelabel(t *testing.T) {
+	s := NewScrambler(1)
+	for i := 0; i < 3; i++ {
+		_, cube := s.RandomState()
+		if relabeled, err := Relabel(cube, cube); err != nil || relabeled != NewSolvedCube() {
+			t.Errorf("Cube relabeled by itself should be solved:\nGot  %s %v", relabeled, err)
+		}
+		if relabeled, err := Relabel(cube, NewSolvedCube()); err != nil || relabeled != cube {
+			t.Errorf("Cube relabeled by a solved cube should not change:\nGot  %s %v\nWant %s", relabeled, err, cube)
+		}
+	}
+	alg := MustParseAlgorithm("R U F' M2 y")
+	from := MustParseAlgorithm("L2 D B").Apply(NewSolvedCube())
+	relabeled, err := Relabel(from, alg.Apply(from))
+	if want := alg.Inverse().Apply(NewSolvedCube()); err != nil || relabeled != want {
+		t.Errorf("Moves bringing a cube to the target should solve the relabeled cube:\nGot  %s %v\nWant %s", relabeled, err, want)
+	}
+}
+
+func TestRelabelInvalid(t *testing.T) {
+	solved := NewSolvedCube()
+	invalid := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	recolored := MustParseCube("WWWWWWWWW GGGGGGGGG RRRRRRRRR BBBBBBBBB OOOOOOOOO YYYYYYYYY")
+	mirrored := MustParseCube("wwwwwwwww ggggggggg ooooooooo bbbbbbbbb rrrrrrrrr yyyyyyyyy")
+	testCases := []struct {
+		From, To Cube
+		Want     error
+	}{
+		{invalid, solved, ErrInvalidCube},
+		{solved, invalid, ErrInvalidCube},
+		{solved, recolored, ErrMismatchedCubes},
+		{solved, mirrored, ErrMismatchedCubes},
+	}
+	for _, tc := range testCases {
+		if _, err := Relabel(tc.From, tc.To); !errors.Is(err, tc.Want) {
+			t.Errorf("Cubes should not be relabeled: %s %s\nGot  %v\nWant %s", tc.From, tc.To, err, tc.Want)
+		}
+	}
+}
+
+func TestSolveBetween(t *testing.T) {
+	s := NewScrambler(1)
+	for i := 0; i < 3; i++ {
+		_, from := s.RandomState()
+		_, to := s.RandomState()
+		solution, err := SolveBetween(from, to)
+		if err != nil || solution.Apply(from) != to || len(solution) > MAX_KOCIEMBA_LENGTH {
+			t.Errorf("Wrong solution from %s to %s:\nGot  %s %v", from, to, solution, err)
+		}
+	}
+}
+
+func TestSolveBetweenSolvers(t *testing.T) {
+	s := NewScrambler(2)
+	for _, name := range SolverNames() {
+		solver, _ := LookupSolver(name)
+		for _, metric := range solver.Capabilities().Metrics {
+			_, from := s.RandomState()
+			alg, _, _ := s.RandomMoves(4, metric.Moves()...)
+			to := alg.Apply(from)
+			solution, err := SolveBetweenWith(context.Background(), solver, from, to, Options{Metric: metric})
+			if err != nil || solution.Apply(from) != to {
+				t.Errorf("Solver %s should bring %s to %s in %s:\nGot  %s %v", name, from, to, metric, solution, err)
+				continue
+			}
+			if solver.Capabilities().Optimal && metric.Length(withoutRotations(solution)) > metric.Length(alg) {
+				t.Errorf("Solver %s should find an optimal solution in %s:\nGot  %s\nWant %s", name, metric, solution, alg)
+			}
+		}
+	}
+}
+
+func TestSolveBetweenRotations(t *testing.T) {
+	from := MustParseAlgorithm("R U F'").Apply(NewSolvedCube())
+	testCases := []struct {
+		Alg    string
+		Metric Metric
+		Length int
+	}{
+		{"y", HTM, 0},
+		{"x2 z", QTM, 0},
+		{"M", STM, 1},
+		{"r", ETM, 2}, // L x: rotations count in ETM
+		{"R U x", HTM, 2},
+	}
+	for _, tc := range testCases {
+		to := MustParseAlgorithm(tc.Alg).Apply(from)
+		solution, err := SolveBetweenContext(context.Background(), from, to, Options{Metric: tc.Metric})
+		if err != nil || solution.Apply(from) != to || tc.Metric.Length(solution) != tc.Length {
+			t.Errorf("Wrong solution for %s in %s:\nGot  %s %v\nWant %d moves", tc.Alg, tc.Metric, solution, err, tc.Length)
+		}
+	}
+	goal := Options{Goal: TargetGoal(from)}
+	if _, err := SolveBetweenContext(context.Background(), from, from, goal); !errors.Is(err, ErrUnsupportedGoal) {
+		t.Errorf("Goal should not be supported:\nGot  %v\nWant %s", err, ErrUnsupportedGoal)
+	}
+}
+
+// Moves of the algorithm, ending rotations apart.
+func withoutRotations(alg Algorithm) Algorithm {
+	for len(alg) > 0 && isRotation(alg[len(alg)-1]) {
+		alg = alg[:len(alg)-1]
+	}
+	return alg
+}
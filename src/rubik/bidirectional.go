@@ -0,0 +1,133 @@
+package rubik
+
+import (
+	"context"
+)
+
+// Bidirectional search, aka meet-in-the-middle.
+//
+// A BFS is run from the scrambled cube, and another one from the solved cube using the inverse
+// moves, always expanding the smallest frontier. As soon as a cube is reached by both of them,
+// the moves from the scrambled cube to this cube, followed by the moves from this cube to the
+// solved cube, make an optimal solution. Each BFS only goes about half as deep as a single BFS
+// would, which roughly doubles the depth reachable with the same memory.
+
+// Solution found by a bidirectional search.
+type BidirectionalSolution struct {
+	Forward  Algorithm // Moves from the scrambled cube to the cube where both searches met
+	Backward Algorithm // Moves from this cube to the solved cube
+}
+
+// All the moves, from the scrambled cube to the solved cube.
+func (s BidirectionalSolution) Algorithm() Algorithm {
+	return append(append(Algorithm{}, s.Forward...), s.Backward...)
+}
+
+// Number of moves from the scrambled cube to the cube where both searches met.
+func (s BidirectionalSolution) MeetingDepth() int {
+	return len(s.Forward)
+}
+
+// Solve the given cube with a bidirectional search, and return a solution that is optimal
+// in the metric of the options, split where both searches met.
+//
+// Return an ErrInvalidCube (as a *ValidationError) if the cube cannot be solved, or
+// a *SearchError wrapping ErrDepthLimit, ErrNodeLimit, ErrMemoryLimit or ErrCanceled
+// if no solution was found within the limits of the options.
+func SolveBidirectional(ctx context.Context, cube Cube, opts Options) (BidirectionalSolution, error) {
+	solution := BidirectionalSolution{}
+	solver := *bidirectionalSolver
+	solver.search = func(cube Cube, c CubieCube, opts Options, budget *searchBudget) (Algorithm, error) {
+		var err error
+		solution, err = solveBidirectional(cube, opts.Moves, opts.maxDepth(MAX_IDA_DEPTH), opts.Workers, budget)
+		return solution.Algorithm(), err
+	}
+	_, err := solver.Solve(ctx, cube, opts)
+	return solution, err
+}
+
+// Solve the cube with a bidirectional search, allowing the given moves only,
+// and looking for solutions of at most `maxDepth` moves with the given number of workers.
+func solveBidirectional(cube Cube, moves []Move, maxDepth, workers int, budget *searchBudget) (BidirectionalSolution, error) {
+	if cube.IsSolved() {
+		return BidirectionalSolution{Algorithm{}, Algorithm{}}, nil
+	}
+	inverses := make([]Move, len(moves))
+	for i, move := range moves {
+		inverses[i] = move.Inverse()
+	}
+	trees := [2]*bfsTree{newBFSTree(cube, moves, cube), newBFSTree(cube, inverses, solvedCubes(cube, moves)...)}
+	for _, t := range trees {
+		t.workers = workers
+		budget.allocate(BFS_NODE_SIZE * len(t.nodes))
+	}
+
+	starts, depths := [2]int{}, [2]int{}
+	for depths[0]+depths[1] < maxDepth {
+		// Expand the tree with the smallest frontier
+		i := 0
+		if len(trees[1].nodes)-starts[1] < len(trees[0].nodes)-starts[0] {
+			i = 1
+		}
+		t, other := trees[i], trees[1-i]
+		end := len(t.nodes)
+		if end == starts[i] {
+			// All the cubes reachable from this side were explored
+			break
+		}
+		node, err := t.expand(starts[i], budget, func(key cubeKey, cube Cube) bool {
+			_, found := other.visited[key]
+			return found
+		})
+		if err != nil {
+			return BidirectionalSolution{}, err
+		}
+		if node >= 0 {
+			nodes := [2]int{}
+			nodes[i], nodes[1-i] = node, int(other.visited[t.nodes[node].key])
+			return BidirectionalSolution{trees[0].path(nodes[0]), trees[1].path(nodes[1]).Inverse()}, nil
+		}
+		starts[i] = end
+		depths[i]++
+	}
+	return BidirectionalSolution{}, budget.failure()
+}
+
+// Solved cubes with the colors of the given cube: each face gets the color of its center.
+// When the moves turn the centers, the solved cube can be reached in any orientation:
+// the cubes obtained by rotating it are solved cubes too.
+func solvedCubes(cube Cube, moves []Move) []Cube {
+	solved := Cube{}
+	for face, center := range centerFacelets {
+		for i := 0; i < 9; i++ {
+			solved[9*face+i] = cube[center]
+		}
+	}
+	cubes := []Cube{solved}
+	if !turnCenters(moves) {
+		return cubes
+	}
+	found := map[Cube]bool{solved: true}
+	for i := 0; i < len(cubes); i++ {
+		for _, rotation := range Rotations {
+			if next := cubes[i].MustTurn(rotation); !found[next] {
+				found[next] = true
+				cubes = append(cubes, next)
+			}
+		}
+	}
+	return cubes
+}
+
+// Return `true` if any of the moves turns a center.
+func turnCenters(moves []Move) bool {
+	for _, move := range moves {
+		p := movePermutations[move]
+		for _, center := range centerFacelets {
+			if p[center] != uint8(center) {
+				return true
+			}
+		}
+	}
+	return false
+}
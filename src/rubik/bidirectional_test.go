@@ -0,0 +1,81 @@
+package rubik
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSolveBidirectional(t *testing.T) {
+	s := NewScrambler(1)
+	for _, length := range []int{1, 2, 5, 8} {
+		scramble, cube, _ := s.RandomMoves(length)
+		want, _ := SolveMetric(cube, HTM)
+		solution, err := SolveBidirectional(context.Background(), cube, Options{})
+		solved := solution.Algorithm()
+		if err != nil || len(solved) != len(want) || !solved.Apply(cube).IsSolved() {
+			t.Errorf("Solution for %s should be optimal:\nGot  %s %v\nWant %s", scramble, solved, err, want)
+		}
+		if depth := solution.MeetingDepth(); depth < 0 || depth > len(solved) || len(solution.Backward) != len(solved)-depth {
+			t.Errorf("Wrong meeting depth for %s:\nGot  %d in (%s) (%s)", scramble, depth, solution.Forward, solution.Backward)
+		}
+	}
+}
+
+func TestSolveBidirectionalMetrics(t *testing.T) {
+	testCases := []struct {
+		Scramble string
+		Metric   Metric
+		Length   int
+	}{
+		{"", HTM, 0},
+		{"U2 R", QTM, 3},
+		{"M2 U E", STM, 3},
+		{"r U' x", ETM, 2},
+	}
+	for _, tc := range testCases {
+		cube := MustParseAlgorithm(tc.Scramble).Apply(NewSolvedCube())
+		solution, err := SolveBidirectional(context.Background(), cube, Options{Metric: tc.Metric})
+		solved := solution.Algorithm()
+		if err != nil || tc.Metric.Length(solved) != tc.Length || !solved.Apply(cube).IsSolved() {
+			t.Errorf("Wrong solution for %q in %s:\nGot  %s %v\nWant %d moves", tc.Scramble, tc.Metric, solved, err, tc.Length)
+		}
+	}
+}
+
+func TestSolveBidirectionalErrors(t *testing.T) {
+	cube := MustParseAlgorithm("R U F' L2 D").Apply(NewSolvedCube())
+	if _, err := SolveBidirectional(context.Background(), cube, Options{MaxDepth: 4}); !errors.Is(err, ErrDepthLimit) {
+		t.Errorf("Search should stop at the maximum depth:\nGot  %v\nWant %s", err, ErrDepthLimit)
+	}
+	if _, err := SolveBidirectional(context.Background(), cube, Options{MaxNodes: 100}); !errors.Is(err, ErrNodeLimit) {
+		t.Errorf("Search should stop after the maximum number of cubes:\nGot  %v\nWant %s", err, ErrNodeLimit)
+	}
+	invalid := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	if _, err := SolveBidirectional(context.Background(), invalid, Options{}); !errors.Is(err, ErrInvalidCube) {
+		t.Errorf("Invalid cube should not be solved:\nGot  %v\nWant %s", err, ErrInvalidCube)
+	}
+}
+
+func TestSolvedCubes(t *testing.T) {
+	cube := MustParseAlgorithm("R U F'").Apply(NewSolvedCube())
+	testCases := []struct {
+		Moves []Move
+		Count int
+	}{
+		{Moves, 1},
+		{QuarterTurns, 1},
+		{STM.Moves(), 24},
+	}
+	for _, tc := range testCases {
+		cubes := solvedCubes(cube, tc.Moves)
+		if len(cubes) != tc.Count {
+			t.Errorf("Wrong number of solved cubes:\nGot  %d\nWant %d", len(cubes), tc.Count)
+		}
+		for _, solved := range cubes {
+			if !solved.IsSolved() {
+				t.Errorf("Cube should be solved: %s", solved)
+			}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package rubik
+
+import (
+	"sync"
+)
+
+// Coordinates of the cube, as used by Kociemba's algorithm.
+//
+// A coordinate is a number describing part of a CubieCube, such as the twist of its corners.
+// Move tables tell how each move changes a coordinate without building any cube, and pruning
+// tables tell how many moves are needed at least to solve the parts described by coordinates.
+// Tables are built the first time they are needed.
+
+// Part of the cube, described by a number between 0 and `size`-1.
+type coordinate struct {
+	size int
+	get  func(c *CubieCube) int
+
+	// Moves the move table is built for. Only the values reached from a solved cube
+	// using these moves are part of the table.
+	moves []Move
+
+	once  sync.Once
+	table []uint16 // Value after each move: table[value*len(moves)+i] for moves[i]
+}
+
+// Twist of the corners: orientation of the first 7 corners in base 3, from 0 to 2186.
+var twistCoord = &coordinate{
+	size: 2187,
+	get: func(c *CubieCube) int {
+		twist := 0
+		for _, co := range c.Co[:7] {
+			twist = 3*twist + co
+		}
+		return twist
+	},
+	moves: Moves,
+}
+
+// Flip of the edges: orientation of the first 11 edges in base 2, from 0 to 2047.
+var flipCoord = &coordinate{
+	size: 2048,
+	get: func(c *CubieCube) int {
+		flip := 0
+		for _, eo := range c.Eo[:11] {
+			flip = 2*flip + eo
+		}
+		return flip
+	},
+	moves: Moves,
+}
+
+// Positions of the 4 edges of the UD slice (FR, FL, BL, BR), whatever their order,
+// from 0 to 494. It is 0 when they all are in the UD slice.
+var sliceCoord = &coordinate{
+	size: 495,
+	get: func(c *CubieCube) int {
+		slice, found := 0, 0
+		for i := BR; i >= UR; i-- {
+			if c.Ep[i] >= FR {
+				found++
+				slice += binomial(int(BR-i), found)
+			}
+		}
+		return slice
+	},
+	moves: Moves,
+}
+
+// Permutation of the corners, from 0 to 40319.
+var cornerPermCoord = &coordinate{
+	size: 40320,
+	get: func(c *CubieCube) int {
+		perm := [8]int{}
+		for i, corner := range c.Cp {
+			perm[i] = int(corner)
+		}
+		return permutationRank(perm[:])
+	},
+	moves: Moves,
+}
+
+// Value of the coordinate after the i-th move of its move set.
+func (coord *coordinate) move(value, i int) int {
+	return int(coord.load()[value*len(coord.moves)+i])
+}
+
+// Return the move table, building it first if needed.
+func (coord *coordinate) load() []uint16 {
+	coord.once.Do(coord.init)
+	return coord.table
+}
+
+// Build the move table, exploring the values reached from a solved cube.
+// A cube is kept for each value found, to compute the effect of the moves on it.
+func (coord *coordinate) init() {
+	n := len(coord.moves)
+	coord.table = make([]uint16, coord.size*n)
+	cubes := make([]*CubieCube, coord.size)
+	solved := NewSolvedCubieCube()
+	start := coord.get(&solved)
+	cubes[start] = &solved
+	queue := []int{start}
+	for len(queue) > 0 {
+		value := queue[0]
+		queue = queue[1:]
+		for i, move := range coord.moves {
+			next := cubes[value].Multiply(cubieMoves[move])
+			to := coord.get(&next)
+			coord.table[value*n+i] = uint16(to)
+			if cubes[to] == nil {
+				cubes[to] = &next
+				queue = append(queue, to)
+			}
+		}
+	}
+}
+
+// Number of moves needed at least to solve the parts of the cube described by coordinates.
+// Coordinates must share the same move set.
+type pruningTable struct {
+	coords []*coordinate
+
+	once   sync.Once
+	depths []int8 // Indexed by the coordinates, the first one varying the slowest
+}
+
+// Number of moves needed at least to solve the given cube.
+func (p *pruningTable) distance(c *CubieCube) int {
+	index := 0
+	for _, coord := range p.coords {
+		index = index*coord.size + coord.get(c)
+	}
+	return p.distanceAt(index)
+}
+
+// Number of moves needed at least to solve cubes with the coordinates at this index.
+func (p *pruningTable) distanceAt(index int) int {
+	return int(p.load()[index])
+}
+
+// Return the depths, building the table first if needed.
+func (p *pruningTable) load() []int8 {
+	p.once.Do(p.init)
+	return p.depths
+}
+
+// Build the table using a BFS from the solved cube.
+func (p *pruningTable) init() {
+	size := 1
+	for _, coord := range p.coords {
+		size *= coord.size
+	}
+	p.depths = make([]int8, size)
+	for i := range p.depths {
+		p.depths[i] = -1
+	}
+
+	solved := NewSolvedCubieCube()
+	start := 0
+	tables := make([][]uint16, len(p.coords))
+	for j, coord := range p.coords {
+		start = start*coord.size + coord.get(&solved)
+		tables[j] = coord.load()
+	}
+	p.depths[start] = 0
+	queue := []int{start}
+	values := make([]int, len(p.coords))
+	n := len(p.coords[0].moves)
+	for len(queue) > 0 {
+		index := queue[0]
+		queue = queue[1:]
+		for j, rest := len(p.coords)-1, index; j >= 0; j-- {
+			values[j] = rest % p.coords[j].size
+			rest /= p.coords[j].size
+		}
+		for i := 0; i < n; i++ {
+			next := 0
+			for j, coord := range p.coords {
+				next = next*coord.size + int(tables[j][values[j]*n+i])
+			}
+			if p.depths[next] < 0 {
+				p.depths[next] = p.depths[index] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+}
+
+// Number of ways to choose k elements among n.
+func binomial(n, k int) int {
+	if k < 0 || k > n {
+		return 0
+	}
+	result := 1
+	for i := 0; i < k; i++ {
+		result = result * (n - i) / (i + 1)
+	}
+	return result
+}
+
+// Rank of the given permutation of 0..n-1 in lexicographic order, from 0 to n!-1.
+func permutationRank(perm []int) int {
+	rank := 0
+	for i := range perm {
+		smaller := 0
+		for _, other := range perm[i+1:] {
+			if other < perm[i] {
+				smaller++
+			}
+		}
+		rank = rank*(len(perm)-i) + smaller
+	}
+	return rank
+}
@@ -0,0 +1,95 @@
+package rubik
+
+import (
+	"testing"
+)
+
+var coordinates = map[string]*coordinate{
+	"twist":       twistCoord,
+	"flip":        flipCoord,
+	"slice":       sliceCoord,
+	"corner perm": cornerPermCoord,
+}
+
+func TestCoordinatesSolved(t *testing.T) {
+	solved := NewSolvedCubieCube()
+	for name, coord := range coordinates {
+		if got := coord.get(&solved); got != 0 {
+			t.Errorf("Wrong %s coordinate for a solved cube:\nGot  %d\nWant 0", name, got)
+		}
+	}
+}
+
+func TestCoordinatesRange(t *testing.T) {
+	for name, coord := range coordinates {
+		coord.once.Do(coord.init)
+		seen := make([]bool, coord.size)
+		for _, value := range coord.table {
+			seen[value] = true
+		}
+		for value, found := range seen {
+			if !found {
+				t.Errorf("Value %d of the %s coordinate should be reached", value, name)
+				break
+			}
+		}
+	}
+}
+
+func TestCoordinateMoves(t *testing.T) {
+	s := NewScrambler(1)
+	for i := 0; i < 100; i++ {
+		c := s.RandomCubieCube()
+		for j, move := range Moves {
+			next := c.Multiply(cubieMoves[move])
+			for name, coord := range coordinates {
+				if got, want := coord.move(coord.get(&c), j), coord.get(&next); got != want {
+					t.Fatalf("Wrong %s coordinate after %s:\nGot  %d\nWant %d", name, move, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestPruningTable(t *testing.T) {
+	table := &pruningTable{coords: []*coordinate{twistCoord, sliceCoord}}
+	table.once.Do(table.init)
+	for i, depth := range table.depths {
+		if depth < 0 {
+			t.Fatalf("Entry %d of the pruning table should be filled", i)
+		}
+	}
+	c := cubieEffect(MustParseAlgorithm("R U F"))
+	if got := table.distance(&c); got != 3 {
+		t.Errorf("Wrong distance for R U F:\nGot  %d\nWant 3", got)
+	}
+}
+
+func TestBinomial(t *testing.T) {
+	testCases := []struct{ N, K, Want int }{
+		{12, 4, 495}, {4, 4, 1}, {3, 4, 0}, {5, 0, 1}, {11, 3, 165},
+	}
+	for _, tc := range testCases {
+		if got := binomial(tc.N, tc.K); got != tc.Want {
+			t.Errorf("Wrong binomial(%d, %d):\nGot  %d\nWant %d", tc.N, tc.K, got, tc.Want)
+		}
+	}
+}
+
+func TestPermutationRank(t *testing.T) {
+	testCases := []struct {
+		Perm []int
+		Want int
+	}{
+		{[]int{0, 1, 2}, 0},
+		{[]int{0, 2, 1}, 1},
+		{[]int{1, 0, 2}, 2},
+		{[]int{2, 1, 0}, 5},
+		{[]int{7, 6, 5, 4, 3, 2, 1, 0}, 40319},
+	}
+	for _, tc := range testCases {
+		if got := permutationRank(tc.Perm); got != tc.Want {
+			t.Errorf("Wrong rank for %v:\nGot  %d\nWant %d", tc.Perm, got, tc.Want)
+		}
+	}
+}
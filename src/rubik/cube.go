@@ -2,6 +2,7 @@ package rubik
 
 import (
 	"strings"
+	"unicode/utf8"
 )
 
 // Representation of the Rubik's Cube.
@@ -16,15 +17,27 @@ const (
 	RED    = 'r'
 )
 
+// Color of a facelet that is unknown, or does not matter: it matches any color.
+const UNKNOWN = '?'
+
 // Moves defined using Singmaster's notation.
 // https://en.wikipedia.org/wiki/Rubik%27s_Cube#Move_notation
 type Move string
 
-var Moves = []Move{
+// Quarter turns of the faces.
+var QuarterTurns = []Move{
 	UP, UP_COUNTER, DOWN, DOWN_COUNTER, LEFT, LEFT_COUNTER,
 	RIGHT, RIGHT_COUNTER, FRONT, FRONT_COUNTER, BACK, BACK_COUNTER,
 }
 
+// Half turns of the faces.
+var HalfTurns = []Move{
+	UP_HALF, DOWN_HALF, LEFT_HALF, RIGHT_HALF, FRONT_HALF, BACK_HALF,
+}
+
+// All face turns: quarter turns, then half turns.
+var Moves = append(append([]Move{}, QuarterTurns...), HalfTurns...)
+
 const (
 	UP            = "U"
 	UP_COUNTER    = "U'"
@@ -38,61 +51,82 @@ const (
 	FRONT_COUNTER = "F'"
 	BACK          = "B"
 	BACK_COUNTER  = "B'"
+
+	UP_HALF    = "U2"
+	DOWN_HALF  = "D2"
+	LEFT_HALF  = "L2"
+	RIGHT_HALF = "R2"
+	FRONT_HALF = "F2"
+	BACK_HALF  = "B2"
 )
 
 // See Cube.pdf for an illustrated version.
-// The cube is a []rune where each face is stored as follows:
+// The cube is a [54]byte where each face is stored as follows:
+//
+//	             #3 BLU
+//	            35 34 33
+//	            32 31 30
+//	            29 28 27
 //
-//              #3 BLU
-//             35 34 33
-//             32 31 30
-//             29 28 27
+//	 #4 ORG      #0 WHT     #2 RED
+//	42 39 36     0  1  2    20 23 26
+//	43 40 37     3  4  5    19 22 25
+//	44 41 38     6  7  8    18 21 24
 //
-//  #4 ORG      #0 WHT     #2 RED
-// 42 39 36     0  1  2    20 23 26
-// 43 40 37     3  4  5    19 22 25
-// 44 41 38     6  7  8    18 21 24
+//	             #1 GRN
+//	             9 10 11
+//	            12 13 14
+//	            15 16 17
 //
-//              #1 GRN
-//              9 10 11
-//             12 13 14
-//             15 16 17
+//	             #5 YLW
+//	            45 46 47
+//	            48 49 50
+//	            51 52 53
 //
-//              #5 YLW
-//             45 46 47
-//             48 49 50
-//             51 52 53
-type Cube []rune
+// Being an array, a Cube is a value: it is copied on assignment, and moves return
+// a new Cube without allocating memory.
+type Cube [9 * 6]byte
 
 // During a move, a facet is moved `From` `To`.
 type Pair struct {
 	From, To int
 }
 
-// Build a new Cube from the given string representation.
+// Parse a Cube from the given string representation.
 //
 // For instance:
 // "bwwbwwyyr orwogbygb gbbrrwrrw ooygbygbr oogoogyyb rrwgywgyo"
 // with white, green, red, blue, orange and yellow faces as described earlier.
 // "wwwwwwwww ggggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy"
 // would correspond to a solved cube.
-// No validity check is performed.
-func NewCube(s string) Cube {
+// Colors must be ASCII characters. Facelets whose color is unknown are written "?".
+// Only the number of facelets is checked. Use Validate for a full validity check.
+func ParseCube(s string) (Cube, error) {
+	var cube Cube
 	stripped := strings.Replace(s, " ", "", -1)
+	if utf8.RuneCountInString(stripped) != 9*6 {
+		return cube, &ParseError{s, ErrInvalidSize}
+	}
 	if len(stripped) != 9*6 {
-		panic("Not a valid cube: " + s)
+		return cube, &ParseError{s, ErrInvalidColor}
 	}
-	cube := make([]rune, 9*6)
-	for i, c := range stripped {
-		cube[i] = c
+	copy(cube[:], stripped)
+	return cube, nil
+}
+
+// Like ParseCube, but panic if the string cannot be parsed.
+func MustParseCube(s string) Cube {
+	cube, err := ParseCube(s)
+	if err != nil {
+		panic(err)
 	}
 	return cube
 }
 
 // Build a new solved Cube.
 func NewSolvedCube() Cube {
-	cube := make([]rune, 9*6)
-	colors := []rune{WHITE, GREEN, RED, BLUE, ORANGE, YELLOW}
+	var cube Cube
+	colors := []byte{WHITE, GREEN, RED, BLUE, ORANGE, YELLOW}
 	for i, color := range colors {
 		for j := 0; j < 9; j++ {
 			cube[i*9+j] = color
@@ -103,22 +137,26 @@ func NewSolvedCube() Cube {
 
 // String representation.
 func (cube Cube) String() string {
-	var s string
+	s := make([]byte, 0, 9*6+5)
 	for i, c := range cube {
 		if i != 0 && i%9 == 0 {
-			s = s + " "
+			s = append(s, ' ')
 		}
-		s = s + string(c)
+		s = append(s, c)
 	}
-	return s
+	return string(s)
 }
 
 // Return `true` if the given face is solved,
-// aka made of a unique color.
-func faceIsSolved(face []rune) bool {
-	ref := face[0]
+// aka made of the color of its center, unknown facelets apart.
+// The center must be known, or the face could be of any color.
+func faceIsSolved(face []byte) bool {
+	ref := face[4]
+	if ref == UNKNOWN {
+		return false
+	}
 	for _, color := range face {
-		if color != ref {
+		if color != UNKNOWN && color != ref {
 			return false
 		}
 	}
@@ -126,6 +164,9 @@ func faceIsSolved(face []rune) bool {
 }
 
 // Return `true` is this cube is solved.
+// The cube does not need to be in its original orientation: each face only has to be
+// made of a unique color. Unknown facelets are ignored, but the center of each face must
+// be known: a cube whose colors are all unknown is not solved.
 func (cube Cube) IsSolved() bool {
 	for f := 0; f < 6*9; f = f + 9 {
 		face := cube[f : f+9]
@@ -136,213 +177,311 @@ func (cube Cube) IsSolved() bool {
 	return true
 }
 
-// Check if two cubes are equal.
+// Check if two cubes are equal. Unknown facelets are only equal to unknown facelets:
+// see Matches to compare cubes whose facelets are not all known.
 func (cube Cube) Equals(other Cube) bool {
-	for i, c := range cube {
-		if c != other[i] {
+	return cube == other
+}
+
+// Return `true` if the facelets of both cubes have the same colors, unless unknown
+// in either of them.
+func (cube Cube) Matches(other Cube) bool {
+	for i, color := range cube {
+		if color != other[i] && color != UNKNOWN && other[i] != UNKNOWN {
 			return false
 		}
 	}
 	return true
 }
 
-// Clone a cube.
+// Return a copy of this cube where the facelets out of the mask are unknown.
+func (cube Cube) Masked(mask Mask) Cube {
+	for i, masked := range mask {
+		if !masked {
+			cube[i] = UNKNOWN
+		}
+	}
+	return cube
+}
+
+// Mask of the facelets whose color is known.
+func (cube Cube) Known() Mask {
+	mask := Mask{}
+	for i, color := range cube {
+		mask[i] = color != UNKNOWN
+	}
+	return mask
+}
+
+// Clone a cube. Since a Cube is a value, this is the same as an assignment.
 func (cube Cube) Copy() Cube {
-	clone := make([]rune, len(cube))
-	copy(clone, cube)
-	return clone
+	return cube
 }
 
-// Turn the cube using the given move, and return a new Cube.
-func (cube Cube) Turn(move Move) Cube {
-	switch move {
-	case UP:
-		return cube.U()
-	case UP_COUNTER:
-		return cube.Uc()
-	case DOWN:
-		return cube.D()
-	case DOWN_COUNTER:
-		return cube.Dc()
-	case LEFT:
-		return cube.L()
-	case LEFT_COUNTER:
-		return cube.Lc()
-	case RIGHT:
-		return cube.R()
-	case RIGHT_COUNTER:
-		return cube.Rc()
-	case FRONT:
-		return cube.F()
-	case FRONT_COUNTER:
-		return cube.Fc()
-	case BACK:
-		return cube.B()
-	case BACK_COUNTER:
-		return cube.Bc()
+// Parse a move written in Singmaster's notation, such as "U", "R'" or "M2".
+// Wide turns may be written either "Rw" or "r".
+func ParseMove(s string) (Move, error) {
+	if len(s) > 0 && strings.IndexByte("udlrfb", s[0]) >= 0 {
+		s = strings.ToUpper(s[:1]) + "w" + s[1:]
+	}
+	if _, found := movePermutations[Move(s)]; found {
+		return Move(s), nil
+	}
+	return "", &ParseError{s, ErrUnknownMove}
+}
+
+// Return the move undoing this one: "U'" for "U", "U" for "U'" and "U2" for "U2".
+func (move Move) Inverse() Move {
+	s := string(move)
+	switch {
+	case strings.HasSuffix(s, "'"):
+		return Move(s[:len(s)-1])
+	case strings.HasSuffix(s, "2"):
+		return move
 	default:
-		panic("Unknown move: " + move)
+		return Move(s + "'")
 	}
 }
 
-// Return a new Cube after Pair.To becomes Pair.From.
-func (cube Cube) exchange(pairs []*Pair) Cube {
-	clone := cube.Copy()
-	for _, pair := range pairs {
-		clone[pair.To] = cube[pair.From]
+// Turn the cube using the given move, and return a new Cube.
+func (cube Cube) TryTurn(move Move) (Cube, error) {
+	p, found := movePermutations[move]
+	if !found {
+		return cube, &ParseError{string(move), ErrUnknownMove}
+	}
+	return cube.permute(p), nil
+}
+
+// Like TryTurn, but panic if the move is unknown.
+func (cube Cube) MustTurn(move Move) Cube {
+	turned, err := cube.TryTurn(move)
+	if err != nil {
+		panic(err)
 	}
-	return clone
+	return turned
+}
+
+// Facelet permutation of each move.
+var movePermutations = map[Move]*Permutation{
+	UP: &permU, UP_COUNTER: &permUc, UP_HALF: &permU2,
+	DOWN: &permD, DOWN_COUNTER: &permDc, DOWN_HALF: &permD2,
+	LEFT: &permL, LEFT_COUNTER: &permLc, LEFT_HALF: &permL2,
+	RIGHT: &permR, RIGHT_COUNTER: &permRc, RIGHT_HALF: &permR2,
+	FRONT: &permF, FRONT_COUNTER: &permFc, FRONT_HALF: &permF2,
+	BACK: &permB, BACK_COUNTER: &permBc, BACK_HALF: &permB2,
 }
 
-// Rotate a face right, starting from `index`.
-func (cube Cube) rotateRight(index int) Cube {
-	clone := cube.Copy()
-	displacements := []int{6, 3, 0, 7, 4, 1, 8, 5, 2}
-	for i, d := range displacements {
-		clone[i+index] = cube[d+index]
+// Facelet displacements when rotating a face right (clockwise) or left.
+var (
+	rotateRight = [9]int{6, 3, 0, 7, 4, 1, 8, 5, 2}
+	rotateLeft  = [9]int{2, 5, 8, 1, 4, 7, 0, 3, 6}
+)
+
+// Build the permutation where each Pair.To becomes Pair.From,
+// then the face starting at `index` is rotated.
+func newPermutation(pairs []*Pair, rotation [9]int, index int) Permutation {
+	exchanged := identity()
+	for _, pair := range pairs {
+		exchanged[pair.To] = uint8(pair.From)
+	}
+	p := exchanged
+	for i, d := range rotation {
+		p[i+index] = exchanged[d+index]
 	}
-	return clone
+	return p
 }
 
-// Rotate a face left, starting from `index`.
-func (cube Cube) rotateLeft(index int) Cube {
-	clone := cube.Copy()
-	displacements := []int{2, 5, 8, 1, 4, 7, 0, 3, 6}
-	for i, d := range displacements {
-		clone[i+index] = cube[d+index]
+// Return a new Cube after applying the given permutation.
+func (cube Cube) permute(p *Permutation) Cube {
+	var moved Cube
+	for i, from := range p {
+		moved[i] = cube[from]
 	}
-	return clone
+	return moved
 }
 
 // Move Front face clockwise, and return a new Cube.
 func (cube Cube) F() Cube {
-	pairs := []*Pair{
-		&Pair{6, 18}, &Pair{7, 21}, &Pair{8, 24},
-		&Pair{18, 47}, &Pair{21, 46}, &Pair{24, 45},
-		&Pair{45, 38}, &Pair{46, 41}, &Pair{47, 44},
-		&Pair{38, 8}, &Pair{41, 7}, &Pair{44, 6},
-	}
-
-	return cube.exchange(pairs).rotateRight(9)
+	return cube.permute(&permF)
 }
 
+var permF = newPermutation([]*Pair{
+	&Pair{6, 18}, &Pair{7, 21}, &Pair{8, 24},
+	&Pair{18, 47}, &Pair{21, 46}, &Pair{24, 45},
+	&Pair{45, 38}, &Pair{46, 41}, &Pair{47, 44},
+	&Pair{38, 8}, &Pair{41, 7}, &Pair{44, 6},
+}, rotateRight, 9)
+
 // Move Front face counter clockwise, and return a new Cube.
 func (cube Cube) Fc() Cube {
-	pairs := []*Pair{
-		&Pair{6, 44}, &Pair{7, 41}, &Pair{8, 38},
-		&Pair{38, 45}, &Pair{41, 46}, &Pair{44, 47},
-		&Pair{45, 24}, &Pair{46, 21}, &Pair{47, 18},
-		&Pair{18, 6}, &Pair{21, 7}, &Pair{24, 8},
-	}
-	return cube.exchange(pairs).rotateLeft(9)
+	return cube.permute(&permFc)
 }
 
+var permFc = newPermutation([]*Pair{
+	&Pair{6, 44}, &Pair{7, 41}, &Pair{8, 38},
+	&Pair{38, 45}, &Pair{41, 46}, &Pair{44, 47},
+	&Pair{45, 24}, &Pair{46, 21}, &Pair{47, 18},
+	&Pair{18, 6}, &Pair{21, 7}, &Pair{24, 8},
+}, rotateLeft, 9)
+
 // Move Back face clockwise, and return a new Cube.
 func (cube Cube) B() Cube {
-	pairs := []*Pair{
-		&Pair{0, 42}, &Pair{1, 39}, &Pair{2, 36},
-		&Pair{36, 51}, &Pair{39, 52}, &Pair{42, 53},
-		&Pair{53, 20}, &Pair{52, 23}, &Pair{51, 26},
-		&Pair{20, 0}, &Pair{23, 1}, &Pair{26, 2},
-	}
-	return cube.exchange(pairs).rotateRight(27)
+	return cube.permute(&permB)
 }
 
+var permB = newPermutation([]*Pair{
+	&Pair{0, 42}, &Pair{1, 39}, &Pair{2, 36},
+	&Pair{36, 51}, &Pair{39, 52}, &Pair{42, 53},
+	&Pair{53, 20}, &Pair{52, 23}, &Pair{51, 26},
+	&Pair{20, 0}, &Pair{23, 1}, &Pair{26, 2},
+}, rotateRight, 27)
+
 // Move Back face counter clockwise, and return a new Cube.
 func (cube Cube) Bc() Cube {
-	pairs := []*Pair{
-		&Pair{0, 20}, &Pair{1, 23}, &Pair{2, 26},
-		&Pair{20, 53}, &Pair{23, 52}, &Pair{26, 51},
-		&Pair{51, 36}, &Pair{52, 39}, &Pair{53, 42},
-		&Pair{36, 2}, &Pair{39, 1}, &Pair{42, 0},
-	}
-	return cube.exchange(pairs).rotateLeft(27)
+	return cube.permute(&permBc)
 }
 
+var permBc = newPermutation([]*Pair{
+	&Pair{0, 20}, &Pair{1, 23}, &Pair{2, 26},
+	&Pair{20, 53}, &Pair{23, 52}, &Pair{26, 51},
+	&Pair{51, 36}, &Pair{52, 39}, &Pair{53, 42},
+	&Pair{36, 2}, &Pair{39, 1}, &Pair{42, 0},
+}, rotateLeft, 27)
+
 // Move Upper face clockwise, and return a new Cube.
 func (cube Cube) U() Cube {
-	pairs := []*Pair{
-		&Pair{9, 36}, &Pair{10, 37}, &Pair{11, 38},
-		&Pair{36, 27}, &Pair{37, 28}, &Pair{38, 29},
-		&Pair{27, 18}, &Pair{28, 19}, &Pair{29, 20},
-		&Pair{18, 9}, &Pair{19, 10}, &Pair{20, 11},
-	}
-	return cube.exchange(pairs).rotateRight(0)
+	return cube.permute(&permU)
 }
 
+var permU = newPermutation([]*Pair{
+	&Pair{9, 36}, &Pair{10, 37}, &Pair{11, 38},
+	&Pair{36, 27}, &Pair{37, 28}, &Pair{38, 29},
+	&Pair{27, 18}, &Pair{28, 19}, &Pair{29, 20},
+	&Pair{18, 9}, &Pair{19, 10}, &Pair{20, 11},
+}, rotateRight, 0)
+
 // Move Upper face counter clockwise, and return a new Cube.
 func (cube Cube) Uc() Cube {
-	pairs := []*Pair{
-		&Pair{9, 18}, &Pair{10, 19}, &Pair{11, 20},
-		&Pair{18, 27}, &Pair{19, 28}, &Pair{20, 29},
-		&Pair{27, 36}, &Pair{28, 37}, &Pair{29, 38},
-		&Pair{36, 9}, &Pair{37, 10}, &Pair{38, 11},
-	}
-	return cube.exchange(pairs).rotateLeft(0)
+	return cube.permute(&permUc)
 }
 
+var permUc = newPermutation([]*Pair{
+	&Pair{9, 18}, &Pair{10, 19}, &Pair{11, 20},
+	&Pair{18, 27}, &Pair{19, 28}, &Pair{20, 29},
+	&Pair{27, 36}, &Pair{28, 37}, &Pair{29, 38},
+	&Pair{36, 9}, &Pair{37, 10}, &Pair{38, 11},
+}, rotateLeft, 0)
+
 // Move Down face clockwise, and return a new Cube.
 func (cube Cube) D() Cube {
-	pairs := []*Pair{
-		&Pair{15, 24}, &Pair{16, 25}, &Pair{17, 26},
-		&Pair{24, 33}, &Pair{25, 34}, &Pair{26, 35},
-		&Pair{33, 42}, &Pair{34, 43}, &Pair{35, 44},
-		&Pair{42, 15}, &Pair{43, 16}, &Pair{44, 17},
-	}
-	return cube.exchange(pairs).rotateRight(45)
+	return cube.permute(&permD)
 }
 
+var permD = newPermutation([]*Pair{
+	&Pair{15, 24}, &Pair{16, 25}, &Pair{17, 26},
+	&Pair{24, 33}, &Pair{25, 34}, &Pair{26, 35},
+	&Pair{33, 42}, &Pair{34, 43}, &Pair{35, 44},
+	&Pair{42, 15}, &Pair{43, 16}, &Pair{44, 17},
+}, rotateRight, 45)
+
 // Move Down face counter clockwise, and return a new Cube.
 func (cube Cube) Dc() Cube {
-	pairs := []*Pair{
-		&Pair{15, 42}, &Pair{16, 43}, &Pair{17, 44},
-		&Pair{42, 33}, &Pair{43, 34}, &Pair{44, 35},
-		&Pair{33, 24}, &Pair{34, 25}, &Pair{35, 26},
-		&Pair{24, 15}, &Pair{25, 16}, &Pair{26, 17},
-	}
-	return cube.exchange(pairs).rotateLeft(45)
+	return cube.permute(&permDc)
 }
 
+var permDc = newPermutation([]*Pair{
+	&Pair{15, 42}, &Pair{16, 43}, &Pair{17, 44},
+	&Pair{42, 33}, &Pair{43, 34}, &Pair{44, 35},
+	&Pair{33, 24}, &Pair{34, 25}, &Pair{35, 26},
+	&Pair{24, 15}, &Pair{25, 16}, &Pair{26, 17},
+}, rotateLeft, 45)
+
 // Move Left face clockwise, and return a new Cube.
 func (cube Cube) L() Cube {
-	pairs := []*Pair{
-		&Pair{0, 9}, &Pair{3, 12}, &Pair{6, 15},
-		&Pair{9, 45}, &Pair{12, 48}, &Pair{15, 51},
-		&Pair{45, 35}, &Pair{48, 32}, &Pair{51, 29},
-		&Pair{29, 6}, &Pair{32, 3}, &Pair{35, 0},
-	}
-	return cube.exchange(pairs).rotateRight(36)
+	return cube.permute(&permL)
 }
 
+var permL = newPermutation([]*Pair{
+	&Pair{0, 9}, &Pair{3, 12}, &Pair{6, 15},
+	&Pair{9, 45}, &Pair{12, 48}, &Pair{15, 51},
+	&Pair{45, 35}, &Pair{48, 32}, &Pair{51, 29},
+	&Pair{29, 6}, &Pair{32, 3}, &Pair{35, 0},
+}, rotateRight, 36)
+
 // Move Left face counter clockwise, and return a new Cube.
 func (cube Cube) Lc() Cube {
-	pairs := []*Pair{
-		&Pair{0, 35}, &Pair{3, 32}, &Pair{6, 29},
-		&Pair{29, 51}, &Pair{32, 48}, &Pair{35, 45},
-		&Pair{45, 9}, &Pair{48, 12}, &Pair{51, 15},
-		&Pair{9, 0}, &Pair{12, 3}, &Pair{15, 6},
-	}
-	return cube.exchange(pairs).rotateLeft(36)
+	return cube.permute(&permLc)
 }
 
+var permLc = newPermutation([]*Pair{
+	&Pair{0, 35}, &Pair{3, 32}, &Pair{6, 29},
+	&Pair{29, 51}, &Pair{32, 48}, &Pair{35, 45},
+	&Pair{45, 9}, &Pair{48, 12}, &Pair{51, 15},
+	&Pair{9, 0}, &Pair{12, 3}, &Pair{15, 6},
+}, rotateLeft, 36)
+
 // Move Right face clockwise, and return a new Cube.
 func (cube Cube) R() Cube {
-	pairs := []*Pair{
-		&Pair{2, 33}, &Pair{5, 30}, &Pair{8, 27},
-		&Pair{27, 53}, &Pair{30, 50}, &Pair{33, 47},
-		&Pair{47, 11}, &Pair{50, 14}, &Pair{53, 17},
-		&Pair{11, 2}, &Pair{14, 5}, &Pair{17, 8},
-	}
-	return cube.exchange(pairs).rotateRight(18)
+	return cube.permute(&permR)
 }
 
+var permR = newPermutation([]*Pair{
+	&Pair{2, 33}, &Pair{5, 30}, &Pair{8, 27},
+	&Pair{27, 53}, &Pair{30, 50}, &Pair{33, 47},
+	&Pair{47, 11}, &Pair{50, 14}, &Pair{53, 17},
+	&Pair{11, 2}, &Pair{14, 5}, &Pair{17, 8},
+}, rotateRight, 18)
+
 // Move Right face counter clockwise, and return a new Cube.
 func (cube Cube) Rc() Cube {
-	pairs := []*Pair{
-		&Pair{2, 11}, &Pair{5, 14}, &Pair{8, 17},
-		&Pair{11, 47}, &Pair{14, 50}, &Pair{17, 53},
-		&Pair{47, 33}, &Pair{50, 30}, &Pair{53, 27},
-		&Pair{27, 8}, &Pair{30, 5}, &Pair{33, 2},
-	}
-	return cube.exchange(pairs).rotateLeft(18)
+	return cube.permute(&permRc)
+}
+
+var permRc = newPermutation([]*Pair{
+	&Pair{2, 11}, &Pair{5, 14}, &Pair{8, 17},
+	&Pair{11, 47}, &Pair{14, 50}, &Pair{17, 53},
+	&Pair{47, 33}, &Pair{50, 30}, &Pair{53, 27},
+	&Pair{27, 8}, &Pair{30, 5}, &Pair{33, 2},
+}, rotateLeft, 18)
+
+// Move Front face twice (half turn), and return a new Cube.
+func (cube Cube) F2() Cube {
+	return cube.permute(&permF2)
+}
+
+var permF2 = permF.Then(permF)
+
+// Move Back face twice (half turn), and return a new Cube.
+func (cube Cube) B2() Cube {
+	return cube.permute(&permB2)
+}
+
+var permB2 = permB.Then(permB)
+
+// Move Upper face twice (half turn), and return a new Cube.
+func (cube Cube) U2() Cube {
+	return cube.permute(&permU2)
 }
+
+var permU2 = permU.Then(permU)
+
+// Move Down face twice (half turn), and return a new Cube.
+func (cube Cube) D2() Cube {
+	return cube.permute(&permD2)
+}
+
+var permD2 = permD.Then(permD)
+
+// Move Left face twice (half turn), and return a new Cube.
+func (cube Cube) L2() Cube {
+	return cube.permute(&permL2)
+}
+
+var permL2 = permL.Then(permL)
+
+// Move Right face twice (half turn), and return a new Cube.
+func (cube Cube) R2() Cube {
+	return cube.permute(&permR2)
+}
+
+var permR2 = permR.Then(permR)
@@ -1,11 +1,12 @@
 package rubik
 
 import (
+	"errors"
 	"testing"
 )
 
 func TestNotSolved(t *testing.T) {
-	cube := NewCube("yrrrrrrrrbbbbbbbbbooooooooogggggggggwwwwwwwwwyyyyyyyyr")
+	cube := MustParseCube("yrrrrrrrrbbbbbbbbbooooooooogggggggggwwwwwwwwwyyyyyyyyr")
 	if cube.IsSolved() {
 		t.Error("Cube should not be solved", cube)
 	}
@@ -19,14 +20,14 @@ func TestNewSolved(t *testing.T) {
 }
 
 func TestSolved(t *testing.T) {
-	cube := NewCube("wwwwwwwww ggggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	cube := MustParseCube("wwwwwwwww ggggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
 	if !cube.IsSolved() {
 		t.Error("Cube should be solved", cube)
 	}
 }
 
 func TestEquals(t *testing.T) {
-	cube1 := NewCube("wwwwwwwww ggggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	cube1 := MustParseCube("wwwwwwwww ggggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
 	cube2 := NewSolvedCube()
 	if !cube1.Equals(cube2) {
 		t.Error("Cubes should be equal", cube1, cube2)
@@ -38,6 +39,64 @@ func TestEquals(t *testing.T) {
 	}
 }
 
+func TestUnknownFacelets(t *testing.T) {
+	cube := MustParseCube("????w???? ????g???? ????r???? ????b???? ????o???? yyyyyyyyy")
+	if got := cube.String(); got != "????w???? ????g???? ????r???? ????b???? ????o???? yyyyyyyyy" {
+		t.Errorf("Wrong string representation:\nGot  %s", got)
+	}
+	if !cube.IsSolved() {
+		t.Error("Cube should be solved, unknown facelets apart", cube)
+	}
+	unknown := MustParseCube("????????? ????????? ????????? ????????? ????????? ?????????")
+	if unknown.IsSolved() {
+		t.Error("Cube of unknown colors should not be solved", unknown)
+	}
+	unknownCenter := MustParseCube("wwww?wwww ????g???? ????r???? ????b???? ????o???? yyyyyyyyy")
+	if unknownCenter.IsSolved() {
+		t.Error("Cube with an unknown center should not be solved", unknownCenter)
+	}
+	if cube.MustTurn(RIGHT).IsSolved() {
+		t.Error("Cube should not be solved once turned", cube.MustTurn(RIGHT))
+	}
+	if got, want := cube.MustTurn(DOWN), cube; got != want {
+		t.Errorf("Turning a solved face should not change the cube:\nGot  %s\nWant %s", got, want)
+	}
+	if got := cube.MustTurn("M").MustTurn("E").MustTurn("E'").MustTurn("M'"); got != cube {
+		t.Errorf("Unknown facelets should follow moves:\nGot  %s\nWant %s", got, cube)
+	}
+	want := MustParseCube("????w???? ????o???? ????g???? ????r???? ????b???? yyyyyyyyy")
+	if got := cube.MustTurn("E"); got != want {
+		t.Errorf("Known facelets should be moved:\nGot  %s\nWant %s", got, want)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	solved := NewSolvedCube()
+	lastLayer := solved.Masked(PiecesMask([]Corner{URF, UFL, ULB, UBR}, []Edge{UR, UF, UL, UB}))
+	testCases := []struct {
+		Scramble string
+		Matches  bool
+	}{
+		{"", true},
+		{"D", true},
+		{"R U R' U' R U R' U' R U R' U' R U R' U' R U R' U' R U R' U'", true},
+		{"U", false},
+		{"R", false},
+	}
+	for _, tc := range testCases {
+		cube := MustParseAlgorithm(tc.Scramble).Apply(solved)
+		if got := lastLayer.Matches(cube); got != tc.Matches || cube.Matches(lastLayer) != tc.Matches {
+			t.Errorf("Wrong match of the last layer for %q:\nGot  %t\nWant %t", tc.Scramble, got, tc.Matches)
+		}
+	}
+	if lastLayer.Equals(solved) {
+		t.Error("Cube with unknown facelets should only equal itself", lastLayer)
+	}
+	if got := lastLayer.Known(); got != PiecesMask([]Corner{URF, UFL, ULB, UBR}, []Edge{UR, UF, UL, UB}) {
+		t.Errorf("Wrong known facelets:\nGot  %v", got)
+	}
+}
+
 //
 // FRONT
 //
@@ -45,7 +104,7 @@ func TestEquals(t *testing.T) {
 func TestF(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.F()
-	target := NewCube("wwwwwwooo ggggggggg wrrwrrwrr bbbbbbbbb ooyooyooy rrryyyyyy")
+	target := MustParseCube("wwwwwwooo ggggggggg wrrwrrwrr bbbbbbbbb ooyooyooy rrryyyyyy")
 	if !target.Equals(turned) {
 		t.Errorf("o F incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -54,7 +113,7 @@ func TestF(t *testing.T) {
 func TestFc(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.Fc()
-	target := NewCube("wwwwwwrrr ggggggggg yrryrryrr bbbbbbbbb oowoowoow oooyyyyyy")
+	target := MustParseCube("wwwwwwrrr ggggggggg yrryrryrr bbbbbbbbb oowoowoow oooyyyyyy")
 	if !target.Equals(turned) {
 		t.Errorf("o F' incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -91,7 +150,7 @@ func TestFcFcFcFc(t *testing.T) {
 func TestB(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.B()
-	target := NewCube("rrrwwwwww ggggggggg rryrryrry bbbbbbbbb woowoowoo yyyyyyooo")
+	target := MustParseCube("rrrwwwwww ggggggggg rryrryrry bbbbbbbbb woowoowoo yyyyyyooo")
 	if !target.Equals(turned) {
 		t.Errorf("o B incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -100,7 +159,7 @@ func TestB(t *testing.T) {
 func TestBc(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.Bc()
-	target := NewCube("ooowwwwww ggggggggg rrwrrwrrw bbbbbbbbb yooyooyoo yyyyyyrrr")
+	target := MustParseCube("ooowwwwww ggggggggg rrwrrwrrw bbbbbbbbb yooyooyoo yyyyyyrrr")
 	if !target.Equals(turned) {
 		t.Errorf("o B' incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -137,7 +196,7 @@ func TestBcBcBcBc(t *testing.T) {
 func TestU(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.U()
-	target := NewCube("wwwwwwwww rrrgggggg bbbrrrrrr ooobbbbbb gggoooooo yyyyyyyyy")
+	target := MustParseCube("wwwwwwwww rrrgggggg bbbrrrrrr ooobbbbbb gggoooooo yyyyyyyyy")
 	if !target.Equals(turned) {
 		t.Errorf("o U incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -146,7 +205,7 @@ func TestU(t *testing.T) {
 func TestUc(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.Uc()
-	target := NewCube("wwwwwwwww ooogggggg gggrrrrrr rrrbbbbbb bbboooooo yyyyyyyyy")
+	target := MustParseCube("wwwwwwwww ooogggggg gggrrrrrr rrrbbbbbb bbboooooo yyyyyyyyy")
 	if !target.Equals(turned) {
 		t.Errorf("o LU' incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -183,7 +242,7 @@ func TestUUc(t *testing.T) {
 func TestD(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.D()
-	target := NewCube("wwwwwwwww ggggggooo rrrrrrggg bbbbbbrrr oooooobbb yyyyyyyyy")
+	target := MustParseCube("wwwwwwwww ggggggooo rrrrrrggg bbbbbbrrr oooooobbb yyyyyyyyy")
 	if !target.Equals(turned) {
 		t.Errorf("o D incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -192,7 +251,7 @@ func TestD(t *testing.T) {
 func TestDc(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.Dc()
-	target := NewCube("wwwwwwwww ggggggrrr rrrrrrbbb bbbbbbooo ooooooggg yyyyyyyyy")
+	target := MustParseCube("wwwwwwwww ggggggrrr rrrrrrbbb bbbbbbooo ooooooggg yyyyyyyyy")
 	if !target.Equals(turned) {
 		t.Errorf("o D' incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -229,7 +288,7 @@ func TestDDc(t *testing.T) {
 func TestL(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.L()
-	target := NewCube("bwwbwwbww wggwggwgg rrrrrrrrr bbybbybby ooooooooo gyygyygyy")
+	target := MustParseCube("bwwbwwbww wggwggwgg rrrrrrrrr bbybbybby ooooooooo gyygyygyy")
 	if !target.Equals(turned) {
 		t.Errorf("o L incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -238,7 +297,7 @@ func TestL(t *testing.T) {
 func TestLc(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.Lc()
-	target := NewCube("gwwgwwgww yggyggygg rrrrrrrrr bbwbbwbbw ooooooooo byybyybyy")
+	target := MustParseCube("gwwgwwgww yggyggygg rrrrrrrrr bbwbbwbbw ooooooooo byybyybyy")
 	if !target.Equals(turned) {
 		t.Errorf("o L' incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -275,7 +334,7 @@ func TestLLc(t *testing.T) {
 func TestR(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.R()
-	target := NewCube("wwgwwgwwg ggyggyggy rrrrrrrrr wbbwbbwbb ooooooooo yybyybyyb")
+	target := MustParseCube("wwgwwgwwg ggyggyggy rrrrrrrrr wbbwbbwbb ooooooooo yybyybyyb")
 	if !target.Equals(turned) {
 		t.Errorf("o R incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -284,7 +343,7 @@ func TestR(t *testing.T) {
 func TestRc(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.Rc()
-	target := NewCube("wwbwwbwwb ggwggwggw rrrrrrrrr ybbybbybb ooooooooo yygyygyyg")
+	target := MustParseCube("wwbwwbwwb ggwggwggw rrrrrrrrr ybbybbybb ooooooooo yygyygyyg")
 	if !target.Equals(turned) {
 		t.Errorf("o R' incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -329,7 +388,7 @@ func TestUFFcUc(t *testing.T) {
 func TestFU(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.F().U()
-	target := NewCube("owwowwoww wrrgggggg bbbwrrwrr ooybbbbbb gggooyooy rrryyyyyy")
+	target := MustParseCube("owwowwoww wrrgggggg bbbwrrwrr ooybbbbbb gggooyooy rrryyyyyy")
 	if !turned.Equals(target) {
 		t.Errorf("o F U incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -338,7 +397,7 @@ func TestFU(t *testing.T) {
 func TestFUU(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.F().U().U()
-	target := NewCube("ooowwwwww bbbgggggg ooywrrwrr gggbbbbbb wrrooyooy rrryyyyyy")
+	target := MustParseCube("ooowwwwww bbbgggggg ooywrrwrr gggbbbbbb wrrooyooy rrryyyyyy")
 	if !turned.Equals(target) {
 		t.Errorf("o F U U incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -347,7 +406,7 @@ func TestFUU(t *testing.T) {
 func Test01(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.F().U().U().Uc()
-	target := NewCube("owwowwoww wrrgggggg bbbwrrwrr ooybbbbbb gggooyooy rrryyyyyy")
+	target := MustParseCube("owwowwoww wrrgggggg bbbwrrwrr ooybbbbbb gggooyooy rrryyyyyy")
 	if !turned.Equals(target) {
 		t.Errorf("o F U U U' incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -356,7 +415,7 @@ func Test01(t *testing.T) {
 func Test02(t *testing.T) {
 	origin := NewSolvedCube()
 	turned := origin.F().R().R().U().L()
-	target := NewCube("bwwbwwyyr orwogbygb gbbrrwrrw ooygbygbr oogoogyyb rrwgywgyo")
+	target := MustParseCube("bwwbwwyyr orwogbygb gbbrrwrrw ooygbygbr oogoogyyb rrwgywgyo")
 	if !turned.Equals(target) {
 		t.Errorf("Test 02 incorrect:\nGot  %s\nWant %s", turned, target)
 	}
@@ -365,3 +424,268 @@ func Test02(t *testing.T) {
 		t.Errorf("Test 02 not returned to origin:\nGot  %s\nWant %s", turned, target)
 	}
 }
+
+//
+// PARSING
+//
+
+func TestParseCube(t *testing.T) {
+	cube, err := ParseCube("wwwwwwwww ggggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	if err != nil || !cube.Equals(NewSolvedCube()) {
+		t.Errorf("Parse incorrect:\nGot  %s %v\nWant %s", cube, err, NewSolvedCube())
+	}
+}
+
+func TestParseCubeInvalidSize(t *testing.T) {
+	_, err := ParseCube("wwwwwwwww ggggggggg rrrrrrrrr")
+	if !errors.Is(err, ErrInvalidSize) {
+		t.Errorf("Parse should fail.\nGot  %v\nWant %s", err, ErrInvalidSize)
+	}
+}
+
+func TestMustParseCubePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParseCube should panic on invalid input")
+		}
+	}()
+	MustParseCube("w")
+}
+
+func TestParseMove(t *testing.T) {
+	move, err := ParseMove("R'")
+	if err != nil || move != RIGHT_COUNTER {
+		t.Errorf("Parse incorrect:\nGot  %s %v\nWant %s", move, err, RIGHT_COUNTER)
+	}
+	_, err = ParseMove("Q")
+	if !errors.Is(err, ErrUnknownMove) {
+		t.Errorf("Parse should fail.\nGot  %v\nWant %s", err, ErrUnknownMove)
+	}
+}
+
+func TestTryTurn(t *testing.T) {
+	origin := NewSolvedCube()
+	for _, move := range Moves {
+		if _, err := origin.TryTurn(move); err != nil {
+			t.Errorf("Move %s should be known: %s", move, err)
+		}
+	}
+	if _, err := origin.TryTurn("Q"); !errors.Is(err, ErrUnknownMove) {
+		t.Errorf("Move should be unknown.\nGot  %v\nWant %s", err, ErrUnknownMove)
+	}
+}
+
+func TestMustTurnPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustTurn should panic on unknown move")
+		}
+	}()
+	NewSolvedCube().MustTurn("Q")
+}
+
+//
+// PERFORMANCE
+//
+
+func TestTurnDoesNotAllocate(t *testing.T) {
+	cube := NewSolvedCube()
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, move := range Moves {
+			cube = cube.MustTurn(move)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Turns should not allocate.\nGot  %v allocations", allocs)
+	}
+}
+
+func TestCubeIsValue(t *testing.T) {
+	origin := NewSolvedCube()
+	copied := origin
+	copied[0] = RED
+	if !origin.IsSolved() {
+		t.Error("Modifying a copy should not modify the original cube", origin)
+	}
+}
+
+// Former []rune implementation, where each move builds its pairs,
+// then copies the cube once to exchange facelets and once more to rotate the face.
+type legacyCube []rune
+
+type legacyMove struct {
+	perm     *Permutation
+	index    int
+	rotation [9]int
+}
+
+var legacyMoves = []legacyMove{
+	{&permU, 0, rotateRight}, {&permUc, 0, rotateLeft},
+	{&permD, 45, rotateRight}, {&permDc, 45, rotateLeft},
+	{&permL, 36, rotateRight}, {&permLc, 36, rotateLeft},
+	{&permR, 18, rotateRight}, {&permRc, 18, rotateLeft},
+	{&permF, 9, rotateRight}, {&permFc, 9, rotateLeft},
+	{&permB, 27, rotateRight}, {&permBc, 27, rotateLeft},
+}
+
+func (cube legacyCube) turn(move legacyMove) legacyCube {
+	pairs := make([]*Pair, 0, 12)
+	for i, from := range move.perm {
+		if int(from) != i && (i < move.index || i >= move.index+9) {
+			pairs = append(pairs, &Pair{int(from), i})
+		}
+	}
+	exchanged := make(legacyCube, len(cube))
+	copy(exchanged, cube)
+	for _, pair := range pairs {
+		exchanged[pair.To] = cube[pair.From]
+	}
+	rotated := make(legacyCube, len(exchanged))
+	copy(rotated, exchanged)
+	for i, d := range move.rotation {
+		rotated[i+move.index] = exchanged[d+move.index]
+	}
+	return rotated
+}
+
+func TestLegacyMatches(t *testing.T) {
+	for i, move := range QuarterTurns {
+		want := NewSolvedCube().F().R().MustTurn(move)
+		origin := NewSolvedCube().F().R()
+		got := legacyCube([]rune(string(origin[:]))).turn(legacyMoves[i])
+		if string(got) != string(want[:]) {
+			t.Errorf("Legacy %s incorrect:\nGot  %s\nWant %s", move, string(got), want)
+		}
+	}
+}
+
+func BenchmarkTurn(b *testing.B) {
+	cube := NewSolvedCube()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cube = cube.MustTurn(Moves[i%len(Moves)])
+	}
+}
+
+func BenchmarkLegacyTurn(b *testing.B) {
+	solved := NewSolvedCube()
+	cube := legacyCube([]rune(string(solved[:])))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cube = cube.turn(legacyMoves[i%len(legacyMoves)])
+	}
+}
+
+//
+// HALF TURNS
+//
+
+func TestHalfTurns(t *testing.T) {
+	origin := NewSolvedCube().F().R()
+	testCases := []struct {
+		Half, Twice Cube
+	}{
+		{origin.U2(), origin.U().U()},
+		{origin.D2(), origin.D().D()},
+		{origin.L2(), origin.L().L()},
+		{origin.R2(), origin.R().R()},
+		{origin.F2(), origin.F().F()},
+		{origin.B2(), origin.B().B()},
+		{origin.MustTurn(UP_HALF), origin.Uc().Uc()},
+	}
+	for _, tc := range testCases {
+		if !tc.Half.Equals(tc.Twice) {
+			t.Errorf("Half turn incorrect:\nGot  %s\nWant %s", tc.Half, tc.Twice)
+		}
+	}
+}
+
+//
+// SLICES, WIDE TURNS AND ROTATIONS
+//
+
+func TestM(t *testing.T) {
+	turned := NewSolvedCube().M()
+	target := MustParseCube("wbwwbwwbw gwggwggwg rrrrrrrrr bybbybbyb ooooooooo ygyygyygy")
+	if !target.Equals(turned) {
+		t.Errorf("o M incorrect:\nGot  %s\nWant %s", turned, target)
+	}
+}
+
+func TestX(t *testing.T) {
+	turned := NewSolvedCube().X()
+	target := MustParseCube("ggggggggg yyyyyyyyy rrrrrrrrr wwwwwwwww ooooooooo bbbbbbbbb")
+	if !target.Equals(turned) {
+		t.Errorf("o x incorrect:\nGot  %s\nWant %s", turned, target)
+	}
+	if !turned.IsSolved() {
+		t.Error("o x should be solved", turned)
+	}
+}
+
+func TestExtendedMovesReturnToOrigin(t *testing.T) {
+	origin := NewSolvedCube().F().R().U()
+	for _, move := range AllMoves {
+		circle := origin.MustTurn(move).MustTurn(move).MustTurn(move).MustTurn(move)
+		if !circle.Equals(origin) {
+			t.Errorf("o %s %s %s %s should return to o", move, move, move, move)
+		}
+	}
+}
+
+func TestExtendedMovesComposition(t *testing.T) {
+	origin := NewSolvedCube().F().R().U()
+	testCases := []struct {
+		Name      string
+		Got, Want Cube
+	}{
+		{"M M'", origin.M().Mc(), origin},
+		{"E E'", origin.E().Ec(), origin},
+		{"S S'", origin.S().Sc(), origin},
+		{"M2", origin.M2(), origin.M().M()},
+		{"x U x'", origin.X().U().Xc(), origin.F()},
+		{"y R y'", origin.Y().R().Yc(), origin.B()},
+		{"z U z'", origin.Z().U().Zc(), origin.L()},
+		{"x y x'", origin.X().Y().Xc(), origin.Z()},
+		{"Rw", origin.Rw(), origin.L().X()},
+		{"Lw'", origin.Lwc(), origin.Rc().X()},
+		{"Uw", origin.Uw(), origin.D().Y()},
+		{"Dw2", origin.Dw2(), origin.U2().Y2()},
+		{"Fw", origin.Fw(), origin.B().Z()},
+		{"Bw'", origin.Bwc(), origin.Fc().Z()},
+	}
+	for _, tc := range testCases {
+		if !tc.Got.Equals(tc.Want) {
+			t.Errorf("o %s incorrect:\nGot  %s\nWant %s", tc.Name, tc.Got, tc.Want)
+		}
+	}
+}
+
+func TestParseExtendedMoves(t *testing.T) {
+	expected := map[string]Move{"M'": MIDDLE_COUNTER, "r": RIGHT_WIDE, "u2": UP_WIDE_HALF, "Fw'": FRONT_WIDE_COUNTER, "x": ROTATE_X}
+	for s, want := range expected {
+		if got, err := ParseMove(s); err != nil || got != want {
+			t.Errorf("Parse %q incorrect:\nGot  %s %v\nWant %s", s, got, err, want)
+		}
+	}
+}
+
+func TestEqualsUpToRotation(t *testing.T) {
+	origin := NewSolvedCube().F().R().U()
+	if !origin.X().Y2().Zc().EqualsUpToRotation(origin) {
+		t.Error("Rotated cube should be equal up to rotation", origin)
+	}
+	if origin.EqualsUpToRotation(origin.R()) {
+		t.Error("Turned cube should not be equal up to rotation", origin)
+	}
+	if len(orientations) != 24 {
+		t.Errorf("Incorrect number of orientations:\nGot  %d\nWant 24", len(orientations))
+	}
+}
+
+func TestValidateRotated(t *testing.T) {
+	cube := NewSolvedCube().F().R().M().Y().Rw()
+	if err := cube.Validate(); err != nil {
+		t.Errorf("Cube with moved centers should be valid: %s\nGot  %s", cube, err)
+	}
+}
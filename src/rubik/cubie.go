@@ -0,0 +1,211 @@
+package rubik
+
+// Representation of the Rubik's Cube at the cubie level.
+//
+// Where the facelet Cube tells the color of each sticker, a CubieCube tells which piece
+// is located at each position, and how it is oriented. Both representations can be
+// converted into each other without loss, and support the same moves.
+
+// Cube described by the permutation and orientation of its corners and edges.
+//
+// A corner orientation (twist) is 0 when its U or D facelet lies on the U or D face,
+// 1 when it is found one step clockwise, 2 when it is found two steps clockwise.
+// An edge orientation (flip) is 0 or 1, following Kociemba's conventions.
+type CubieCube struct {
+	Cp [8]Corner // Corner found at each corner position
+	Co [8]int    // Twist of the corner found at each position
+	Ep [12]Edge  // Edge found at each edge position
+	Eo [12]int   // Flip of the edge found at each position
+
+	// Color of each face, as given by its center.
+	Colors [6]byte
+}
+
+// Build a new solved CubieCube, colored as NewSolvedCube.
+func NewSolvedCubieCube() CubieCube {
+	c := CubieCube{}
+	for i := range c.Cp {
+		c.Cp[i] = Corner(i)
+	}
+	for i := range c.Ep {
+		c.Ep[i] = Edge(i)
+	}
+	copy(c.Colors[:], []byte{WHITE, GREEN, RED, BLUE, ORANGE, YELLOW})
+	return c
+}
+
+// Convert this cube to its cubie representation.
+//
+// Return an error wrapping ErrInvalidCube if the cube cannot be reached from a solved cube.
+func (cube Cube) ToCubie() (CubieCube, error) {
+	if err := cube.Validate(); err != nil {
+		return CubieCube{}, err
+	}
+	faces, _ := cube.facesByColor()
+	c, _ := cube.locatePieces(faces)
+	for face, i := range centerFacelets {
+		c.Colors[face] = cube[i]
+	}
+	return *c, nil
+}
+
+// Convert this cube to its facelet representation.
+func (c CubieCube) ToFacelets() Cube {
+	var cube Cube
+	for face, i := range centerFacelets {
+		cube[i] = c.Colors[face]
+	}
+	for i, facelets := range cornerFacelets {
+		home := cornerFaces(c.Cp[i])
+		for n := 0; n < 3; n++ {
+			cube[facelets[(n+c.Co[i])%3]] = c.Colors[home[n]]
+		}
+	}
+	for i, facelets := range edgeFacelets {
+		home := edgeFaces(c.Ep[i])
+		for n := 0; n < 2; n++ {
+			cube[facelets[(n+c.Eo[i])%2]] = c.Colors[home[n]]
+		}
+	}
+	return cube
+}
+
+// Return `true` is this cube is solved.
+func (c CubieCube) IsSolved() bool {
+	for i := range c.Cp {
+		if c.Cp[i] != Corner(i) || c.Co[i] != 0 {
+			return false
+		}
+	}
+	for i := range c.Ep {
+		if c.Ep[i] != Edge(i) || c.Eo[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Return the position of the given corner, and its twist.
+// Return `false` if the corner is not in the cube.
+func (c CubieCube) FindCorner(corner Corner) (Corner, int, bool) {
+	for i, cp := range c.Cp {
+		if cp == corner {
+			return Corner(i), c.Co[i], true
+		}
+	}
+	return 0, 0, false
+}
+
+// Return the position of the given edge, and its flip.
+// Return `false` if the edge is not in the cube.
+func (c CubieCube) FindEdge(edge Edge) (Edge, int, bool) {
+	for i, ep := range c.Ep {
+		if ep == edge {
+			return Edge(i), c.Eo[i], true
+		}
+	}
+	return 0, 0, false
+}
+
+// Apply the permutation and orientation changes of `other` to this cube,
+// and return a new CubieCube. Colors are those of this cube.
+func (c CubieCube) Multiply(other CubieCube) CubieCube {
+	product := c
+	for i := range product.Cp {
+		product.Cp[i] = c.Cp[other.Cp[i]]
+		product.Co[i] = (c.Co[other.Cp[i]] + other.Co[i]) % 3
+	}
+	for i := range product.Ep {
+		product.Ep[i] = c.Ep[other.Ep[i]]
+		product.Eo[i] = (c.Eo[other.Ep[i]] + other.Eo[i]) % 2
+	}
+	return product
+}
+
+// Turn the cube using the given move, and return a new CubieCube.
+func (c CubieCube) TryTurn(move Move) (CubieCube, error) {
+	if m, found := cubieMoves[move]; found {
+		return c.Multiply(m), nil
+	}
+	// Slice turns, wide turns and rotations also move the centers:
+	// apply them to the facelets, then locate the pieces relative to the new centers.
+	cube, err := c.ToFacelets().TryTurn(move)
+	if err != nil {
+		return CubieCube{}, err
+	}
+	return cube.ToCubie()
+}
+
+// Like TryTurn, but panic if the move is unknown.
+func (c CubieCube) MustTurn(move Move) CubieCube {
+	turned, err := c.TryTurn(move)
+	if err != nil {
+		panic(err)
+	}
+	return turned
+}
+
+// Effect of each move on a solved CubieCube.
+var cubieMoves = map[Move]CubieCube{}
+
+// Effect of the clockwise face moves, from Kociemba's definitions.
+func init() {
+	clockwise := map[Move]CubieCube{
+		UP: {
+			Cp: [8]Corner{UBR, URF, UFL, ULB, DFR, DLF, DBL, DRB},
+			Ep: [12]Edge{UB, UR, UF, UL, DR, DF, DL, DB, FR, FL, BL, BR},
+		},
+		DOWN: {
+			Cp: [8]Corner{URF, UFL, ULB, UBR, DLF, DBL, DRB, DFR},
+			Ep: [12]Edge{UR, UF, UL, UB, DF, DL, DB, DR, FR, FL, BL, BR},
+		},
+		LEFT: {
+			Cp: [8]Corner{URF, ULB, DBL, UBR, DFR, UFL, DLF, DRB},
+			Co: [8]int{0, 1, 2, 0, 0, 2, 1, 0},
+			Ep: [12]Edge{UR, UF, BL, UB, DR, DF, FL, DB, FR, UL, DL, BR},
+		},
+		RIGHT: {
+			Cp: [8]Corner{DFR, UFL, ULB, URF, DRB, DLF, DBL, UBR},
+			Co: [8]int{2, 0, 0, 1, 1, 0, 0, 2},
+			Ep: [12]Edge{FR, UF, UL, UB, BR, DF, DL, DB, DR, FL, BL, UR},
+		},
+		FRONT: {
+			Cp: [8]Corner{UFL, DLF, ULB, UBR, URF, DFR, DBL, DRB},
+			Co: [8]int{1, 2, 0, 0, 2, 1, 0, 0},
+			Ep: [12]Edge{UR, FL, UL, UB, DR, FR, DL, DB, UF, DF, BL, BR},
+			Eo: [12]int{0, 1, 0, 0, 0, 1, 0, 0, 1, 1, 0, 0},
+		},
+		BACK: {
+			Cp: [8]Corner{URF, UFL, UBR, DRB, DFR, DLF, ULB, DBL},
+			Co: [8]int{0, 0, 1, 2, 0, 0, 2, 1},
+			Ep: [12]Edge{UR, UF, UL, BR, DR, DF, DL, BL, FR, FL, UB, DB},
+			Eo: [12]int{0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 1, 1},
+		},
+	}
+	counter := map[Move]Move{
+		UP: UP_COUNTER, DOWN: DOWN_COUNTER, LEFT: LEFT_COUNTER,
+		RIGHT: RIGHT_COUNTER, FRONT: FRONT_COUNTER, BACK: BACK_COUNTER,
+	}
+	half := map[Move]Move{
+		UP: UP_HALF, DOWN: DOWN_HALF, LEFT: LEFT_HALF,
+		RIGHT: RIGHT_HALF, FRONT: FRONT_HALF, BACK: BACK_HALF,
+	}
+	for move, m := range clockwise {
+		cubieMoves[move] = m
+		cubieMoves[half[move]] = m.Multiply(m)
+		cubieMoves[counter[move]] = m.Multiply(m).Multiply(m)
+	}
+}
+
+var cornerNames = [8]string{"URF", "UFL", "ULB", "UBR", "DFR", "DLF", "DBL", "DRB"}
+var edgeNames = [12]string{"UR", "UF", "UL", "UB", "DR", "DF", "DL", "DB", "FR", "FL", "BL", "BR"}
+
+// String representation.
+func (corner Corner) String() string {
+	return cornerNames[corner]
+}
+
+// String representation.
+func (edge Edge) String() string {
+	return edgeNames[edge]
+}
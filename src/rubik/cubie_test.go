@@ -0,0 +1,99 @@
+package rubik
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestToCubieSolved(t *testing.T) {
+	c, err := NewSolvedCube().ToCubie()
+	if err != nil || c != NewSolvedCubieCube() {
+		t.Errorf("Solved cube conversion incorrect:\nGot  %v %v\nWant %v", c, err, NewSolvedCubieCube())
+	}
+	if !c.IsSolved() {
+		t.Error("Solved cubie cube should be solved", c)
+	}
+}
+
+func TestToCubieInvalid(t *testing.T) {
+	cube := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	if _, err := cube.ToCubie(); !errors.Is(err, ErrInvalidCube) {
+		t.Errorf("Invalid cube should not be converted.\nGot  %v\nWant %s", err, ErrInvalidCube)
+	}
+}
+
+func TestCubieRoundTrip(t *testing.T) {
+	cubes := []Cube{
+		NewSolvedCube(),
+		NewSolvedCube().F().R().R().U().L(),
+		MustParseCube("bwwbwwyyr orwogbygb gbbrrwrrw ooygbygbr oogoogyyb rrwgywgyo"),
+		MustParseCube("sssssssss qqqqqqqqq ppppppppp nnnnnnnnn mmmmmmmmm lllllllll").B().Dc().L(),
+	}
+	for _, cube := range cubes {
+		c, err := cube.ToCubie()
+		if err != nil {
+			t.Errorf("Conversion failed for %s: %s", cube, err)
+			continue
+		}
+		back := c.ToFacelets()
+		if !back.Equals(cube) {
+			t.Errorf("Round trip incorrect:\nGot  %s\nWant %s", back, cube)
+		}
+	}
+}
+
+func TestCubieMovesMatchFacelets(t *testing.T) {
+	cube := NewSolvedCube()
+	c := NewSolvedCubieCube()
+	for i := 0; i < 200; i++ {
+		move := Moves[(i*i+3*i)%len(Moves)]
+		cube = cube.MustTurn(move)
+		c = c.MustTurn(move)
+
+		converted, err := cube.ToCubie()
+		if err != nil {
+			t.Fatalf("Conversion failed after %d moves: %s", i+1, err)
+		}
+		if converted != c {
+			t.Fatalf("Move %s differs after %d moves:\nGot  %v\nWant %v", move, i+1, c, converted)
+		}
+		if !c.ToFacelets().Equals(cube) {
+			t.Fatalf("Move %s differs after %d moves:\nGot  %s\nWant %s", move, i+1, c.ToFacelets(), cube)
+		}
+	}
+}
+
+func TestCubieEachMove(t *testing.T) {
+	for _, move := range AllMoves {
+		want := NewSolvedCube().MustTurn(move)
+		got := NewSolvedCubieCube().MustTurn(move).ToFacelets()
+		if !got.Equals(want) {
+			t.Errorf("o %s incorrect:\nGot  %s\nWant %s", move, got, want)
+		}
+	}
+}
+
+func TestCubieUnknownMove(t *testing.T) {
+	if _, err := NewSolvedCubieCube().TryTurn("Q"); !errors.Is(err, ErrUnknownMove) {
+		t.Errorf("Move should be unknown.\nGot  %v\nWant %s", err, ErrUnknownMove)
+	}
+}
+
+func TestFindPieces(t *testing.T) {
+	c := NewSolvedCubieCube().MustTurn(RIGHT)
+	if pos, twist, found := c.FindCorner(URF); pos != UBR || twist != 1 || !found {
+		t.Errorf("o R: URF corner incorrect:\nGot  %s %d %v\nWant %s %d", pos, twist, found, UBR, 1)
+	}
+	if pos, flip, found := c.FindEdge(UR); pos != BR || flip != 0 || !found {
+		t.Errorf("o R: UR edge incorrect:\nGot  %s %d %v\nWant %s %d", pos, flip, found, BR, 0)
+	}
+
+	// Pieces are exported, so that a cube may lack some of them
+	c.Cp[UFL], c.Ep[UF] = URF, UR
+	if _, _, found := c.FindCorner(UFL); found {
+		t.Error("Missing UFL corner should not be found", c.Cp)
+	}
+	if _, _, found := c.FindEdge(UF); found {
+		t.Error("Missing UF edge should not be found", c.Ep)
+	}
+}
@@ -0,0 +1,93 @@
+package rubik
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by this package.
+//
+// Functions and methods of this package return errors rather than panic on bad input.
+// The panicking forms are only provided as `Must*` helpers, for inputs known to be valid.
+
+var (
+	// The cube does not have 54 facelets.
+	ErrInvalidSize = errors.New("rubik: cube must have 54 facelets")
+
+	// The cube contains colors that are not ASCII characters.
+	ErrInvalidColor = errors.New("rubik: cube colors must be ASCII characters")
+
+	// The move is not part of the supported notation.
+	ErrUnknownMove = errors.New("rubik: unknown move")
+
+	// The algorithm notation cannot be parsed. See SyntaxError for details.
+	ErrSyntax = errors.New("rubik: invalid algorithm notation")
+
+	// The cube cannot be reached from a solved cube. See ValidationError for details.
+	ErrInvalidCube = errors.New("rubik: invalid cube")
+
+	// The moves cannot be chained into a scramble without repeating the same layer.
+	ErrInvalidMoveSet = errors.New("rubik: moves cannot be chained into a scramble")
+
+	// The solver gave up before finding a solution. See SearchError for details.
+	ErrNoSolution = errors.New("rubik: no solution found")
+
+	// No solution exists within the maximum depth of the search.
+	ErrDepthLimit = errors.New("rubik: no solution within the maximum depth")
+
+	// The search explored the maximum number of cubes without finding a solution.
+	ErrNodeLimit = errors.New("rubik: maximum number of explored cubes reached")
+
+	// The search used its whole memory budget without finding a solution.
+	ErrMemoryLimit = errors.New("rubik: memory budget exceeded")
+
+	// The search was canceled, or its deadline passed.
+	ErrCanceled = errors.New("rubik: search canceled")
+
+	// No solver is registered with this name.
+	ErrUnknownSolver = errors.New("rubik: unknown solver")
+
+	// A solver is already registered with this name.
+	ErrDuplicateSolver = errors.New("rubik: solver already registered")
+
+	// The solver does not support the metric of the options.
+	ErrUnsupportedMetric = errors.New("rubik: metric not supported")
+
+	// The solver only solves cubes, and does not reach other goals.
+	ErrUnsupportedGoal = errors.New("rubik: goal not supported")
+
+	// The solver uses all the moves of the metric, and does not restrict them.
+	ErrUnsupportedMoves = errors.New("rubik: restricted moves not supported")
+
+	// The allowed moves cannot solve the cube: it is out of the subgroup they generate.
+	ErrUnreachable = errors.New("rubik: cube cannot be solved with the allowed moves")
+
+	// The cubes do not have the same pieces: one cannot be turned into the other.
+	ErrMismatchedCubes = errors.New("rubik: cubes do not have the same pieces")
+
+	// The pattern lists no pieces, or lists the same piece twice.
+	ErrInvalidPattern = errors.New("rubik: invalid pattern")
+
+	// The file is not a pattern database, or is truncated.
+	ErrInvalidPatternDatabase = errors.New("rubik: invalid pattern database")
+
+	// The pattern database was written by an incompatible version of this package.
+	ErrPatternDatabaseVersion = errors.New("rubik: unsupported pattern database version")
+
+	// The content of the pattern database does not match its checksum.
+	ErrPatternDatabaseChecksum = errors.New("rubik: pattern database checksum mismatch")
+)
+
+// Error raised when parsing a cube or a move from its string representation.
+type ParseError struct {
+	Input string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %q", e.Err, e.Input)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
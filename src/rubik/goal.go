@@ -0,0 +1,278 @@
+package rubik
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Goals of a search.
+//
+// Searches stop on solved cubes unless given another goal: a target cube, a target whose
+// facelets only matter within a mask (such as the cross, or the first two layers), or any
+// predicate. Goals may come with a heuristic, that IDA* uses to prune its search.
+//
+// Masked goals get a heuristic of their own: for the pieces whose facelets are all known and
+// in the mask, the moves needed to bring them where the target has them are read from small
+// pattern databases of MASK_PATTERN_PIECES pieces, built the first time they are needed.
+
+// Cubes a search is looking for.
+type Goal interface {
+	// Return `true` if the cube reaches the goal.
+	//
+	// Parallel searches call this method from several goroutines.
+	IsReached(cube Cube) bool
+}
+
+// Function used as a Goal.
+type GoalFunc func(cube Cube) bool
+
+// Call the function.
+func (f GoalFunc) IsReached(cube Cube) bool {
+	return f(cube)
+}
+
+// Goal with a lower bound of the number of moves needed to reach it.
+type heuristicGoal struct {
+	Goal
+	Heuristic
+}
+
+// Build a goal estimating the moves needed to reach it with the given heuristic, which must
+// never overestimate for solutions to be optimal.
+func WithHeuristic(goal Goal, heuristic Heuristic) Goal {
+	return heuristicGoal{goal, heuristic}
+}
+
+// Goal reached by solved cubes, the goal of searches given none.
+var SolvedGoal = WithHeuristic(GoalFunc(Cube.IsSolved), DefaultHeuristic)
+
+// Set of facelets, by index.
+type Mask [54]bool
+
+// Build a mask holding the facelets of the given pieces, and the centers.
+func PiecesMask(corners []Corner, edges []Edge) Mask {
+	mask := Mask{}
+	for _, center := range centerFacelets {
+		mask[center] = true
+	}
+	for _, corner := range corners {
+		for _, facelet := range cornerFacelets[corner] {
+			mask[facelet] = true
+		}
+	}
+	for _, edge := range edges {
+		for _, facelet := range edgeFacelets[edge] {
+			mask[facelet] = true
+		}
+	}
+	return mask
+}
+
+// Masks of the usual steps of layer-by-layer methods, solving the D layer first.
+var (
+	CrossMask      = PiecesMask(nil, []Edge{DR, DF, DL, DB})
+	FirstLayerMask = PiecesMask([]Corner{DFR, DLF, DBL, DRB}, []Edge{DR, DF, DL, DB})
+	F2LMask        = PiecesMask([]Corner{DFR, DLF, DBL, DRB}, []Edge{DR, DF, DL, DB, FR, FL, BL, BR})
+)
+
+// Build a mask holding all the facelets.
+func FullMask() Mask {
+	mask := Mask{}
+	for i := range mask {
+		mask[i] = true
+	}
+	return mask
+}
+
+// Number of pieces of each pattern database of the heuristic of masked goals.
+const MASK_PATTERN_PIECES = 4
+
+// Goal reached by the given cube only, unknown facelets of the target apart.
+//
+// When the target can be reached from a solved cube, the moves needed to reach it are
+// estimated with DefaultHeuristic.
+func TargetGoal(target Cube) Goal {
+	return MaskedGoal(target, FullMask())
+}
+
+// Goal reached by cubes having the colors of the target on the facelets of the mask.
+// Other facelets of the target, and unknown ones, are ignored.
+//
+// The moves needed to reach the goal are estimated from the pieces whose facelets are all
+// known and in the mask.
+func MaskedGoal(target Cube, mask Mask) Goal {
+	for i, known := range target.Known() {
+		mask[i] = mask[i] && known
+	}
+	g := &maskedGoal{target: target, mask: mask}
+	if c, err := target.ToCubie(); err == nil && mask == FullMask() {
+		return WithHeuristic(g, &maskedHeuristic{inverse: c.Inverse(), colors: c.Colors, heuristic: DefaultHeuristic})
+	}
+	c, corners, edges, ok := completeTarget(target, mask)
+	if !ok || len(corners)+len(edges) == 0 {
+		return g
+	}
+	// Moves bringing a cube `x` to the target also solve the pieces of the target in the
+	// cube `target⁻¹ x`, as positions of the target become the pieces of the solved cube.
+	h := &maskedHeuristic{inverse: c.Inverse(), colors: c.Colors}
+	for i := 0; i < len(corners); i += MASK_PATTERN_PIECES {
+		h.patterns = append(h.patterns, Pattern{Corners: corners[i:min(i+MASK_PATTERN_PIECES, len(corners))]})
+	}
+	for i := 0; i < len(edges); i += MASK_PATTERN_PIECES {
+		h.patterns = append(h.patterns, Pattern{Edges: edges[i:min(i+MASK_PATTERN_PIECES, len(edges))]})
+	}
+	return WithHeuristic(g, h)
+}
+
+// Cube having the pieces of the target at the positions whose facelets are all in the mask,
+// and other pieces anywhere else, along with these positions.
+//
+// Return `false` if the centers are not all in the mask, or if pieces of the target do not
+// exist or appear twice: the heuristic cannot tell which piece goes where.
+func completeTarget(target Cube, mask Mask) (CubieCube, []Corner, []Edge, bool) {
+	c := CubieCube{}
+	faces := map[byte]int{}
+	for face, i := range centerFacelets {
+		if _, found := faces[target[i]]; found || !mask[i] {
+			return c, nil, nil, false
+		}
+		faces[target[i]] = face
+		c.Colors[face] = target[i]
+	}
+	faceOfColor := func(i int) (int, bool) {
+		face, found := faces[target[i]]
+		return face, found && mask[i]
+	}
+
+	var corners []Corner
+	knownCorners, usedCorners := [8]bool{}, [8]bool{}
+	for i, facelets := range cornerFacelets {
+		f := [3]int{}
+		known := true
+		for j, facelet := range facelets {
+			var ok bool
+			f[j], ok = faceOfColor(facelet)
+			known = known && ok
+		}
+		if !known {
+			continue
+		}
+		corner, twist, found := findCorner(f)
+		if !found || usedCorners[corner] {
+			return c, nil, nil, false
+		}
+		c.Cp[i], c.Co[i] = corner, twist
+		knownCorners[i], usedCorners[corner] = true, true
+		corners = append(corners, Corner(i))
+	}
+	var edges []Edge
+	knownEdges, usedEdges := [12]bool{}, [12]bool{}
+	for i, facelets := range edgeFacelets {
+		f0, ok0 := faceOfColor(facelets[0])
+		f1, ok1 := faceOfColor(facelets[1])
+		if !ok0 || !ok1 {
+			continue
+		}
+		edge, flip, found := findEdge([2]int{f0, f1})
+		if !found || usedEdges[edge] {
+			return c, nil, nil, false
+		}
+		c.Ep[i], c.Eo[i] = edge, flip
+		knownEdges[i], usedEdges[edge] = true, true
+		edges = append(edges, Edge(i))
+	}
+
+	// Other pieces, in any order
+	for i, next := 0, 0; i < len(c.Cp); i++ {
+		if !knownCorners[i] {
+			for usedCorners[next] {
+				next++
+			}
+			c.Cp[i], usedCorners[next] = Corner(next), true
+		}
+	}
+	for i, next := 0, 0; i < len(c.Ep); i++ {
+		if !knownEdges[i] {
+			for usedEdges[next] {
+				next++
+			}
+			c.Ep[i], usedEdges[next] = Edge(next), true
+		}
+	}
+	return c, corners, edges, true
+}
+
+// Return the only cube reaching the goal, if the goal is a target whose facelets are all
+// known and masked, as with TargetGoal.
+func goalTarget(goal Goal) (Cube, bool) {
+	switch g := goal.(type) {
+	case heuristicGoal:
+		return goalTarget(g.Goal)
+	case *maskedGoal:
+		return g.target, g.mask == FullMask()
+	}
+	return Cube{}, false
+}
+
+// Goal reached by cubes matching a target on the facelets of a mask.
+type maskedGoal struct {
+	target Cube
+	mask   Mask
+}
+
+func (g *maskedGoal) IsReached(cube Cube) bool {
+	for i, masked := range g.mask {
+		if masked && cube[i] != g.target[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Heuristic of a masked goal.
+type maskedHeuristic struct {
+	inverse CubieCube // Inverse of the target, completed with the pieces out of the mask
+	colors  [6]byte   // Colors of the target: other cubes cannot reach it
+
+	heuristic Heuristic // Heuristic of the whole target, if all the facelets are masked
+	patterns  []Pattern // Pieces solved in the target, once multiplied by its inverse, by groups
+	once      sync.Once
+}
+
+func (h *maskedHeuristic) Estimate(c CubieCube) int {
+	if c.Colors != h.colors {
+		return 0
+	}
+	h.once.Do(func() {
+		if h.heuristic != nil {
+			return
+		}
+		heuristics := make([]Heuristic, len(h.patterns))
+		for i, pattern := range h.patterns {
+			heuristics[i] = maskPatternDatabase(pattern)
+		}
+		h.heuristic = MaxHeuristic(heuristics...)
+	})
+	return h.heuristic.Estimate(h.inverse.Multiply(c))
+}
+
+// Pattern databases of masked goals, by pattern.
+var maskPatternDatabases = map[string]*PatternDatabase{}
+var maskPatternDatabasesLock sync.Mutex
+
+// Return the pattern database of the given pattern, built the first time it is needed.
+func maskPatternDatabase(pattern Pattern) *PatternDatabase {
+	maskPatternDatabasesLock.Lock()
+	defer maskPatternDatabasesLock.Unlock()
+	key := fmt.Sprint(pattern.Corners, pattern.Edges)
+	if db, found := maskPatternDatabases[key]; found {
+		return db
+	}
+	db, err := NewPatternDatabase(pattern)
+	if err != nil {
+		// Patterns of masks list 1 to MASK_PATTERN_PIECES different pieces, and are always valid
+		panic(err)
+	}
+	maskPatternDatabases[key] = db
+	return db
+}
@@ -0,0 +1,173 @@
+package rubik
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPiecesMask(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Mask  Mask
+		Count int
+	}{
+		{"cross", CrossMask, 14},
+		{"first layer", FirstLayerMask, 26},
+		{"F2L", F2LMask, 34},
+		{"full", FullMask(), 54},
+		{"centers", PiecesMask(nil, nil), 6},
+	}
+	for _, tc := range testCases {
+		count := 0
+		for _, masked := range tc.Mask {
+			if masked {
+				count++
+			}
+		}
+		if count != tc.Count {
+			t.Errorf("Wrong number of facelets in the %s mask:\nGot  %d\nWant %d", tc.Name, count, tc.Count)
+		}
+	}
+}
+
+func TestMaskedGoal(t *testing.T) {
+	goal := MaskedGoal(NewSolvedCube(), CrossMask)
+	testCases := []struct {
+		Scramble string
+		Reached  bool
+	}{
+		{"", true},
+		{"U R U' R'", true},
+		{"R U R'", true},
+		{"D", false},
+		{"R", false},
+		{"F2 U2 F2", false},
+	}
+	for _, tc := range testCases {
+		cube := MustParseAlgorithm(tc.Scramble).Apply(NewSolvedCube())
+		if got := goal.IsReached(cube); got != tc.Reached {
+			t.Errorf("Wrong cross goal for %q:\nGot  %t\nWant %t", tc.Scramble, got, tc.Reached)
+		}
+	}
+}
+
+func TestMaskedGoalHeuristic(t *testing.T) {
+	s := NewScrambler(1)
+	target := MustParseAlgorithm("R U R' U'").Apply(NewSolvedCube())
+	for _, mask := range []Mask{CrossMask, FirstLayerMask, F2LMask, FullMask()} {
+		h, ok := MaskedGoal(target, mask).(Heuristic)
+		if !ok {
+			t.Fatalf("Masked goal should have a heuristic")
+		}
+		if c, _ := target.ToCubie(); h.Estimate(c) != 0 {
+			t.Errorf("Target should be estimated 0 moves away:\nGot  %d", h.Estimate(c))
+		}
+		for i := 0; i < 10; i++ {
+			alg, _, _ := s.RandomMoves(4)
+			c, _ := alg.Inverse().Apply(target).ToCubie()
+			if estimate := h.Estimate(c); estimate > 4 {
+				t.Errorf("Heuristic should not overestimate for %s:\nGot  %d\nWant at most 4", alg, estimate)
+			}
+		}
+	}
+	if _, ok := MaskedGoal(NewSolvedCube(), PiecesMask(nil, nil)).(Heuristic); ok {
+		t.Errorf("Goal without whole pieces should not have a heuristic")
+	}
+	unknownCenter := NewSolvedCube()
+	unknownCenter[4] = UNKNOWN
+	duplicate := NewSolvedCube()
+	duplicate[5], duplicate[19] = WHITE, GREEN
+	for _, target := range []Cube{unknownCenter, duplicate} {
+		if _, ok := TargetGoal(target).(Heuristic); ok {
+			t.Errorf("Goal should not have a heuristic when pieces cannot be located: %s", target)
+		}
+	}
+}
+
+func TestMaskedGoalUnknown(t *testing.T) {
+	target := MustParseAlgorithm("R U R' U'").Apply(NewSolvedCube()).Masked(F2LMask)
+	goal := TargetGoal(target)
+	if !goal.IsReached(MustParseAlgorithm("R U R' U' U2").Apply(NewSolvedCube())) {
+		t.Errorf("Unknown facelets of the target should match any color")
+	}
+	h, ok := goal.(Heuristic)
+	if !ok {
+		t.Fatalf("Goal should have a heuristic")
+	}
+	s := NewScrambler(1)
+	for i := 0; i < 10; i++ {
+		alg, _, _ := s.RandomMoves(3)
+		cube := alg.Inverse().Apply(MustParseAlgorithm("R U R' U'").Apply(NewSolvedCube()))
+		c, _ := cube.ToCubie()
+		if estimate := h.Estimate(c); estimate > 3 {
+			t.Errorf("Heuristic should not overestimate for %s:\nGot  %d\nWant at most 3", alg, estimate)
+		}
+		solution, err := SolveContext(context.Background(), cube, Options{Goal: goal})
+		if err != nil || len(solution) > 3 || !target.Matches(solution.Apply(cube)) {
+			t.Errorf("Wrong solution for %s:\nGot  %s %v", alg, solution, err)
+		}
+	}
+}
+
+func TestSolveGoal(t *testing.T) {
+	checkerboard := MustParseAlgorithm("R2 L2 U2 D2 F2 B2").Apply(NewSolvedCube())
+	dFace := GoalFunc(func(cube Cube) bool {
+		return faceIsSolved(cube[45:54])
+	})
+	testCases := []struct {
+		Scramble string
+		Goal     Goal
+		Length   int
+	}{
+		{"", TargetGoal(checkerboard), 6},
+		{"R U F'", TargetGoal(NewSolvedCube()), 3},
+		{"R U F'", TargetGoal(MustParseAlgorithm("R U").Apply(NewSolvedCube())), 1},
+		{"R U F' L2 D B' U R2 F L' D2", MaskedGoal(NewSolvedCube(), CrossMask), 7},
+		{"F R U R' U' F'", MaskedGoal(NewSolvedCube(), F2LMask), 0},
+		{"R U R'", MaskedGoal(NewSolvedCube(), F2LMask), 3},
+		{"F R U R' U' F'", MaskedGoal(NewSolvedCube(), FirstLayerMask), 0},
+		{"R U R' U R U2 R' U", dFace, 0},
+		{"R U", dFace, 2},
+	}
+	for _, tc := range testCases {
+		cube := MustParseAlgorithm(tc.Scramble).Apply(NewSolvedCube())
+		solution, err := SolveContext(context.Background(), cube, Options{Goal: tc.Goal})
+		if err != nil || len(solution) != tc.Length || !tc.Goal.IsReached(solution.Apply(cube)) {
+			t.Errorf("Wrong solution for %q:\nGot  %s %v\nWant %d moves", tc.Scramble, solution, err, tc.Length)
+		}
+	}
+}
+
+func TestSolveGoalOptimal(t *testing.T) {
+	s := NewScrambler(1)
+	goal := MaskedGoal(NewSolvedCube(), CrossMask)
+	for i := 0; i < 5; i++ {
+		scramble, cube, _ := s.RandomMoves(5)
+		want, err := bfsSolver.Solve(context.Background(), cube, Options{Goal: goal})
+		if err != nil {
+			t.Fatalf("BFS failed on %s: %s", scramble, err)
+		}
+		for _, workers := range []int{1, 3} {
+			got, err := idaSolver.Solve(context.Background(), cube, Options{Goal: goal, Workers: workers})
+			if err != nil || len(got) != len(want) || !goal.IsReached(got.Apply(cube)) {
+				t.Errorf("Cross of %s should be solved optimally:\nGot  %s %v\nWant %s", scramble, got, err, want)
+			}
+		}
+	}
+	_, cube := s.RandomState()
+	solution, err := SolveContext(context.Background(), cube, Options{Goal: goal, Metric: QTM})
+	if err != nil || QTM.Length(solution) > 12 || !goal.IsReached(solution.Apply(cube)) {
+		t.Errorf("Cross of a random cube should be solved:\nGot  %s %v", solution, err)
+	}
+}
+
+func TestSolveGoalUnsupported(t *testing.T) {
+	goal := MaskedGoal(NewSolvedCube(), CrossMask)
+	for _, name := range []string{"bidirectional", "kociemba", "thistlethwaite"} {
+		solver, _ := LookupSolver(name)
+		if _, err := solver.Solve(context.Background(), NewSolvedCube(), Options{Goal: goal}); !errors.Is(err, ErrUnsupportedGoal) {
+			t.Errorf("Solver %s should not support goals:\nGot  %v\nWant %s", name, err, ErrUnsupportedGoal)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package rubik
+
+import (
+	"context"
+	"strings"
+)
+
+// A solver using iterative-deepening A* (IDA*).
+//
+// The solver explores move sequences depth first, with an increasing bound on their length.
+// A heuristic gives a lower bound of the number of moves needed to solve each cube: sequences
+// that cannot be completed within the bound are pruned. As long as the heuristic never
+// overestimates (it is admissible), the first solution found is optimal. Memory usage is
+// limited to the current sequence of moves.
+
+// Longest solution searched for. Any cube can be solved in 20 moves in the half turn metric.
+const MAX_IDA_DEPTH = 26
+
+// Lower bound of the number of moves needed to solve a cube.
+//
+// Heuristics must never overestimate, in the half turn metric, for solutions to be optimal.
+type Heuristic interface {
+	Estimate(c CubieCube) int
+}
+
+// Function used as a Heuristic.
+type HeuristicFunc func(c CubieCube) int
+
+// Call the function.
+func (f HeuristicFunc) Estimate(c CubieCube) int {
+	return f(c)
+}
+
+// Heuristic estimating 0 moves for any cube: the search becomes an iterative deepening DFS.
+var NoHeuristic Heuristic = HeuristicFunc(func(c CubieCube) int { return 0 })
+
+// Moves needed to orient the corners and place the UD slice edges in their slice.
+var TwistSliceHeuristic Heuristic = &pruningTable{coords: []*coordinate{twistCoord, sliceCoord}}
+
+// Moves needed to orient the edges and place the UD slice edges in their slice.
+var FlipSliceHeuristic Heuristic = &pruningTable{coords: []*coordinate{flipCoord, sliceCoord}}
+
+// Moves needed to place the corners, whatever their twist.
+var CornerPermutationHeuristic Heuristic = &pruningTable{coords: []*coordinate{cornerPermCoord}}
+
+// Heuristic used by Solve: the best of the heuristics above.
+var DefaultHeuristic = MaxHeuristic(TwistSliceHeuristic, FlipSliceHeuristic, CornerPermutationHeuristic)
+
+// Number of moves needed at least to solve the given cube.
+func (p *pruningTable) Estimate(c CubieCube) int {
+	return p.distance(&c)
+}
+
+// Build a heuristic returning the highest estimate of the given heuristics.
+// It is admissible if they all are.
+func MaxHeuristic(heuristics ...Heuristic) Heuristic {
+	return HeuristicFunc(func(c CubieCube) int {
+		estimate := 0
+		for _, h := range heuristics {
+			if e := h.Estimate(c); e > estimate {
+				estimate = e
+			}
+		}
+		return estimate
+	})
+}
+
+// Solve the given cube using IDA* guided by the given heuristic, and return a list of moves.
+// The solution is optimal in the half turn metric if the heuristic is admissible.
+//
+// Return an ErrInvalidCube (as a *ValidationError) if the cube cannot be solved,
+// or ErrNoSolution if no solution was found within MAX_IDA_DEPTH moves.
+func SolveIDA(cube Cube, heuristic Heuristic) (Algorithm, error) {
+	return SolveContext(context.Background(), cube, Options{Heuristic: heuristic})
+}
+
+// Solve the cube using IDA*, allowing the given face moves only, reaching the given goal
+// (solved cubes if nil), and looking for solutions of at most `maxDepth` moves with the given
+// number of workers.
+func solveIDA(c CubieCube, moves []Move, goal Goal, heuristic Heuristic, maxDepth, workers int, budget *searchBudget) (Algorithm, error) {
+	s := newIDASearch(moves, goal, heuristic, budget)
+	for bound := heuristic.Estimate(c); bound <= maxDepth; bound++ {
+		if workers > 1 {
+			if solution, found := s.searchParallel(c, bound, workers); found {
+				return solution, nil
+			}
+		} else if s.search(c, bound) {
+			return s.path, nil
+		}
+		if budget.err != nil {
+			break
+		}
+	}
+	return nil, budget.failure()
+}
+
+// State of an IDA* search.
+type idaSearch struct {
+	moves        []Move
+	quarterTurns bool // Only quarter turns are allowed
+	goal         Goal // Solved cubes if nil
+	heuristic    Heuristic
+	path         Algorithm
+	budget       *searchBudget
+}
+
+// Build a search allowing the given face moves only, with an empty path.
+func newIDASearch(moves []Move, goal Goal, heuristic Heuristic, budget *searchBudget) *idaSearch {
+	s := &idaSearch{moves: moves, quarterTurns: true, goal: goal, heuristic: heuristic, path: Algorithm{}, budget: budget}
+	for _, move := range moves {
+		if isHalfTurn(move) {
+			s.quarterTurns = false
+		}
+	}
+	return s
+}
+
+// Look for a solution of at most `bound` more moves, appending them to the path.
+func (s *idaSearch) search(c CubieCube, bound int) bool {
+	if s.isReached(c) {
+		return true
+	}
+	if bound == 0 || !s.budget.visit() || s.heuristic.Estimate(c) > bound {
+		return false
+	}
+	for _, move := range s.moves {
+		if isRedundant(s.path, move, s.quarterTurns) {
+			continue
+		}
+		s.path = append(s.path, move)
+		if s.search(c.Multiply(cubieMoves[move]), bound-1) {
+			return true
+		}
+		s.path = s.path[:len(s.path)-1]
+		if s.budget.err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// Return `true` if the cube reaches the goal of the search.
+func (s *idaSearch) isReached(c CubieCube) bool {
+	if s.goal == nil {
+		return c.IsSolved()
+	}
+	return s.goal.IsReached(c.ToFacelets())
+}
+
+// Faces in the order they appear in Moves, opposite faces side by side.
+const faceOrder = "UDLRFB"
+
+// Return `true` if `move` never needs to follow the moves of `path` in a shortest solution:
+// turning the same face twice in a row can be done in one move (or two clockwise quarter
+// turns when only quarter turns are allowed, X' X' being the same as X X), and turning
+// opposite faces commute, so that they are only turned in the order of Moves.
+func isRedundant(path Algorithm, move Move, quarterTurns bool) bool {
+	if len(path) == 0 {
+		return false
+	}
+	previous := path[len(path)-1]
+	p := strings.IndexByte(faceOrder, previous[0])
+	m := strings.IndexByte(faceOrder, move[0])
+	if p == m {
+		return !quarterTurns || previous != move || len(move) > 1 ||
+			(len(path) > 1 && path[len(path)-2][0] == move[0])
+	}
+	return p/2 == m/2 && m < p
+}
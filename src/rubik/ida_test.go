@@ -0,0 +1,99 @@
+package rubik
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSolveIDA(t *testing.T) {
+	s := NewScrambler(1)
+	for _, length := range []int{1, 3, 5, 8, 10} {
+		scramble, cube, _ := s.RandomMoves(length)
+		solved, err := SolveIDA(cube, DefaultHeuristic)
+		if err != nil {
+			t.Fatalf("Error while solving %s: %s", scramble, err)
+		}
+		if len(solved) > length || !solved.Apply(cube).IsSolved() {
+			t.Errorf("Wrong solution for %s\nGot  %s", scramble, solved)
+		}
+	}
+}
+
+func TestSolveIDAOptimal(t *testing.T) {
+	// Without any heuristic, IDA* finds optimal solutions
+	s := NewScrambler(2)
+	for i := 0; i < 10; i++ {
+		scramble, cube, _ := s.RandomMoves(5)
+		want, _ := SolveIDA(cube, NoHeuristic)
+		got, err := SolveIDA(cube, DefaultHeuristic)
+		if err != nil || len(got) != len(want) {
+			t.Errorf("Solution for %s should be optimal\nGot  %s %v\nWant %s", scramble, got, err, want)
+		}
+	}
+}
+
+func TestHeuristicsAdmissible(t *testing.T) {
+	heuristics := map[string]Heuristic{
+		"twist slice":        TwistSliceHeuristic,
+		"flip slice":         FlipSliceHeuristic,
+		"corner permutation": CornerPermutationHeuristic,
+		"default":            DefaultHeuristic,
+	}
+	s := NewScrambler(3)
+	for i := 0; i < 200; i++ {
+		scramble, _, _ := s.RandomMoves(1 + i%12)
+		c := cubieEffect(scramble)
+		for name, h := range heuristics {
+			if estimate := h.Estimate(c); estimate > len(scramble) {
+				t.Errorf("%s heuristic overestimates %s: %d", name, scramble, estimate)
+			}
+		}
+	}
+}
+
+func TestMaxHeuristic(t *testing.T) {
+	one := HeuristicFunc(func(c CubieCube) int { return 1 })
+	two := HeuristicFunc(func(c CubieCube) int { return 2 })
+	if got := MaxHeuristic(two, one).Estimate(NewSolvedCubieCube()); got != 2 {
+		t.Errorf("Wrong estimate:\nGot  %d\nWant 2", got)
+	}
+	if got := MaxHeuristic().Estimate(NewSolvedCubieCube()); got != 0 {
+		t.Errorf("Wrong estimate without heuristics:\nGot  %d\nWant 0", got)
+	}
+}
+
+func TestSolveIDAInvalid(t *testing.T) {
+	cube := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	if _, err := SolveIDA(cube, DefaultHeuristic); !errors.Is(err, ErrInvalidCube) {
+		t.Errorf("Invalid cube should not be solved.\nGot  %v\nWant %s", err, ErrInvalidCube)
+	}
+}
+
+func TestIsRedundant(t *testing.T) {
+	testCases := []struct {
+		Path         string
+		Move         Move
+		QuarterTurns bool
+		Want         bool
+	}{
+		{"", UP, false, false},
+		{"U", UP, false, true},
+		{"U", UP_HALF, false, true},
+		{"U", DOWN, false, false},
+		{"D", UP, false, true},
+		{"R", LEFT, false, true},
+		{"L", RIGHT, false, false},
+		{"U", FRONT, false, false},
+		{"U", UP, true, false},
+		{"U U", UP, true, true},
+		{"U", UP_COUNTER, true, true},
+		{"U'", UP_COUNTER, true, true},
+		{"R", UP_COUNTER, true, false},
+		{"D U", UP, true, false},
+	}
+	for _, tc := range testCases {
+		if got := isRedundant(MustParseAlgorithm(tc.Path), tc.Move, tc.QuarterTurns); got != tc.Want {
+			t.Errorf("Wrong redundancy of %s after %q:\nGot  %v\nWant %v", tc.Move, tc.Path, got, tc.Want)
+		}
+	}
+}
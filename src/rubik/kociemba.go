@@ -0,0 +1,277 @@
+package rubik
+
+import (
+	"context"
+	"time"
+)
+
+// Kociemba's two-phase algorithm.
+//
+// Phase 1 brings the cube into the subgroup G1 = <U, D, R2, L2, F2, B2>, where corners and
+// edges are oriented and the UD slice edges are in their slice. Phase 2 solves the cube using
+// the moves of G1 only. Both phases are IDA* searches over coordinates, using move tables and
+// pruning tables. Longer phase 1 solutions are tried afterwards, since they may lead to
+// shorter solutions overall.
+// http://kociemba.org/cube.htm
+
+// Length of the solutions Solve is satisfied with.
+const KOCIEMBA_TARGET_LENGTH = 21
+
+// Time after which Solve is satisfied with the shortest solution found so far.
+const KOCIEMBA_TIME_LIMIT = 500 * time.Millisecond
+
+// Longest solution searched for by Kociemba's algorithm.
+const MAX_KOCIEMBA_LENGTH = 30
+
+// Longest phase 2 solution. Any cube of G1 can be solved in 18 moves of G1.
+const MAX_PHASE2_LENGTH = 18
+
+// Moves of the subgroup G1, used in phase 2.
+var phase2Moves = []Move{
+	UP, UP_COUNTER, DOWN, DOWN_COUNTER,
+	UP_HALF, DOWN_HALF, LEFT_HALF, RIGHT_HALF, FRONT_HALF, BACK_HALF,
+}
+
+// Permutation of the corners, from 0 to 40319, for phase 2 moves.
+var cornerPerm2Coord = &coordinate{
+	size:  40320,
+	get:   cornerPermCoord.get,
+	moves: phase2Moves,
+}
+
+// Permutation of the 8 edges of the U and D faces, from 0 to 40319.
+// Only defined for cubes of G1.
+var udEdgePermCoord = &coordinate{
+	size: 40320,
+	get: func(c *CubieCube) int {
+		perm := [8]int{}
+		for i, edge := range c.Ep[:8] {
+			perm[i] = int(edge)
+		}
+		return permutationRank(perm[:])
+	},
+	moves: phase2Moves,
+}
+
+// Permutation of the 4 edges of the UD slice, from 0 to 23.
+// Only defined for cubes of G1.
+var slicePermCoord = &coordinate{
+	size: 24,
+	get: func(c *CubieCube) int {
+		perm := [4]int{}
+		for i, edge := range c.Ep[8:] {
+			perm[i] = int(edge - FR)
+		}
+		return permutationRank(perm[:])
+	},
+	moves: phase2Moves,
+}
+
+var (
+	twistSliceTable  = TwistSliceHeuristic.(*pruningTable)
+	flipSliceTable   = FlipSliceHeuristic.(*pruningTable)
+	twistFlipTable   = &pruningTable{coords: []*coordinate{twistCoord, flipCoord}}
+	cornerSliceTable = &pruningTable{coords: []*coordinate{cornerPerm2Coord, slicePermCoord}}
+	edgeSliceTable   = &pruningTable{coords: []*coordinate{udEdgePermCoord, slicePermCoord}}
+)
+
+// Solve the given cube using Kociemba's algorithm, and return a list of moves.
+// Stop as soon as a solution of at most KOCIEMBA_TARGET_LENGTH moves is found, or return
+// the shortest solution found after KOCIEMBA_TIME_LIMIT.
+//
+// Return an ErrInvalidCube (as a *ValidationError) if the cube cannot be solved.
+func SolveKociemba(cube Cube) (Algorithm, error) {
+	c, err := cube.ToCubie()
+	if err != nil {
+		return nil, err
+	}
+	s := newKociembaSearch(c)
+	s.target = KOCIEMBA_TARGET_LENGTH
+	s.deadline = time.Now().Add(KOCIEMBA_TIME_LIMIT)
+	return s.run()
+}
+
+// Like SolveKociemba, but keep searching for shorter solutions until the deadline,
+// or until the solution found is known to be optimal in the half turn metric.
+// The search goes on after the deadline as long as no solution is found.
+func SolveKociembaUntil(cube Cube, deadline time.Time) (Algorithm, error) {
+	c, err := cube.ToCubie()
+	if err != nil {
+		return nil, err
+	}
+	s := newKociembaSearch(c)
+	s.deadline = deadline
+	return s.run()
+}
+
+// Solve the cube using Kociemba's algorithm, stopping as soon as a solution of at most
+// `target` moves is found. Unlike SolveKociemba, the result does not depend on timing.
+func solveKociemba(c CubieCube, target int) Algorithm {
+	s := newKociembaSearch(c)
+	s.target = target
+	solution, _ := s.run()
+	return solution
+}
+
+// Prepare a search, building the tables if needed.
+func newKociembaSearch(c CubieCube) *kociembaSearch {
+	return &kociembaSearch{
+		cube:      c,
+		path:      Algorithm{},
+		maxLength: MAX_KOCIEMBA_LENGTH + 1,
+		budget:    &searchBudget{ctx: context.Background()},
+
+		twistMoves:       twistCoord.load(),
+		flipMoves:        flipCoord.load(),
+		sliceMoves:       sliceCoord.load(),
+		cornerMoves:      cornerPerm2Coord.load(),
+		edgeMoves:        udEdgePermCoord.load(),
+		slicePermMoves:   slicePermCoord.load(),
+		twistSliceDepths: twistSliceTable.load(),
+		flipSliceDepths:  flipSliceTable.load(),
+		twistFlipDepths:  twistFlipTable.load(),
+		cornerDepths:     cornerSliceTable.load(),
+		edgeDepths:       edgeSliceTable.load(),
+	}
+}
+
+// Search phase 1 solutions of increasing length, and return the best solution found.
+func (s *kociembaSearch) run() (Algorithm, error) {
+	c := &s.cube
+	twist, flip, slice := twistCoord.get(c), flipCoord.get(c), sliceCoord.get(c)
+	for depth := 0; depth < s.maxLength && !s.stopped; depth++ {
+		s.phase1(twist, flip, slice, depth)
+	}
+	if s.best == nil {
+		return nil, s.budget.failure()
+	}
+	return s.best, nil
+}
+
+// State of a search using Kociemba's algorithm.
+type kociembaSearch struct {
+	cube         CubieCube
+	path         Algorithm // Moves of phase 1, then of phase 2
+	phase1Length int
+
+	best      Algorithm // Shortest solution found so far
+	maxLength int       // Only solutions shorter than this are searched for
+	target    int       // Stop as soon as a solution this short is found, if any
+	deadline  time.Time // Stop after this time, once a solution is found
+	budget    *searchBudget
+	stopped   bool
+
+	// Tables, loaded before the search
+	twistMoves, flipMoves, sliceMoves                                            []uint16
+	cornerMoves, edgeMoves, slicePermMoves                                       []uint16
+	twistSliceDepths, flipSliceDepths, twistFlipDepths, cornerDepths, edgeDepths []int8
+}
+
+// Look for phase 1 solutions of exactly `togo` more moves, and complete them with phase 2.
+func (s *kociembaSearch) phase1(twist, flip, slice, togo int) {
+	if s.stopped {
+		return
+	}
+	if !s.budget.visit() {
+		s.stopped = true
+		return
+	}
+	if s.budget.nodes%4096 == 0 && s.best != nil && !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		s.stopped = true
+		return
+	}
+	if togo == 0 {
+		// Phase 1 solutions ending with a move of G1 were already tried without this move
+		if twist == 0 && flip == 0 && slice == 0 &&
+			(len(s.path) == 0 || !isPhase2Move(s.path[len(s.path)-1])) {
+			s.startPhase2()
+		}
+		return
+	}
+	d1 := int(s.twistSliceDepths[twist*sliceCoord.size+slice])
+	d2 := int(s.flipSliceDepths[flip*sliceCoord.size+slice])
+	d3 := int(s.twistFlipDepths[twist*flipCoord.size+flip])
+	if d1 > togo || d2 > togo || d3 > togo {
+		return
+	}
+	n := len(Moves)
+	for i, move := range Moves {
+		if isRedundant(s.path, move, false) {
+			continue
+		}
+		s.path = append(s.path, move)
+		s.phase1(int(s.twistMoves[twist*n+i]), int(s.flipMoves[flip*n+i]), int(s.sliceMoves[slice*n+i]), togo-1)
+		s.path = s.path[:len(s.path)-1]
+	}
+}
+
+// Solve the cube reached after phase 1 with the shortest phase 2 solution, if it leads to
+// a solution shorter than the best one so far.
+func (s *kociembaSearch) startPhase2() {
+	c := s.cube
+	for _, move := range s.path {
+		c = c.Multiply(cubieMoves[move])
+	}
+	corners, edges, slice := cornerPerm2Coord.get(&c), udEdgePermCoord.get(&c), slicePermCoord.get(&c)
+	phase1Length := len(s.path)
+	s.phase1Length = phase1Length
+	// The last move of phase 1 may be merged with the first move of phase 2
+	maxDepth := s.maxLength - phase1Length
+	if maxDepth > MAX_PHASE2_LENGTH {
+		maxDepth = MAX_PHASE2_LENGTH
+	}
+	depth := int(s.cornerDepths[corners*slicePermCoord.size+slice])
+	if d := int(s.edgeDepths[edges*slicePermCoord.size+slice]); d > depth {
+		depth = d
+	}
+	for ; depth <= maxDepth; depth++ {
+		if s.phase2(corners, edges, slice, depth) {
+			solution := s.path.Simplify()
+			if len(solution) >= s.maxLength {
+				break
+			}
+			s.best = solution
+			s.maxLength = len(s.best)
+			if len(s.best) <= s.target {
+				s.stopped = true
+			}
+			break
+		}
+	}
+	s.path = s.path[:phase1Length]
+}
+
+// Look for a phase 2 solution of at most `togo` more moves, appending them to the path.
+func (s *kociembaSearch) phase2(corners, edges, slice, togo int) bool {
+	if corners == 0 && edges == 0 && slice == 0 {
+		return true
+	}
+	d1 := int(s.cornerDepths[corners*slicePermCoord.size+slice])
+	d2 := int(s.edgeDepths[edges*slicePermCoord.size+slice])
+	if d1 > togo || d2 > togo {
+		return false
+	}
+	n := len(phase2Moves)
+	for i, move := range phase2Moves {
+		if isRedundant(s.path[s.phase1Length:], move, false) {
+			continue
+		}
+		s.path = append(s.path, move)
+		if s.phase2(int(s.cornerMoves[corners*n+i]), int(s.edgeMoves[edges*n+i]),
+			int(s.slicePermMoves[slice*n+i]), togo-1) {
+			return true
+		}
+		s.path = s.path[:len(s.path)-1]
+	}
+	return false
+}
+
+// Return `true` if the move belongs to G1.
+func isPhase2Move(move Move) bool {
+	for _, m := range phase2Moves {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
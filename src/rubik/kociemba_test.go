@@ -0,0 +1,106 @@
+package rubik
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSolveKociemba(t *testing.T) {
+	s := NewScrambler(1)
+	for i := 0; i < 10; i++ {
+		scramble, cube := s.RandomState()
+		solved, err := SolveKociemba(cube)
+		if err != nil {
+			t.Fatalf("Error while solving %s: %s", scramble, err)
+		}
+		if len(solved) > MAX_KOCIEMBA_LENGTH || !solved.Apply(cube).IsSolved() {
+			t.Errorf("Wrong solution for %s\nGot  %s", scramble, solved)
+		}
+	}
+}
+
+func TestSolveKociembaShort(t *testing.T) {
+	testCases := []struct {
+		Scramble string
+		Solution string
+	}{
+		{"", ""},
+		{"F", "F'"},
+		{"U2", "U2"},
+		{"R U", "U' R'"},
+		{"R2 U F'", "F U' R2"},
+	}
+	for _, tc := range testCases {
+		cube := MustParseAlgorithm(tc.Scramble).Apply(NewSolvedCube())
+		solved, err := SolveKociemba(cube)
+		if err != nil || solved.String() != tc.Solution {
+			t.Errorf("Wrong solution for %q\nGot  %s %v\nWant %s", tc.Scramble, solved, err, tc.Solution)
+		}
+	}
+}
+
+func TestSolveKociembaUntil(t *testing.T) {
+	s := NewScrambler(2)
+	for i := 0; i < 3; i++ {
+		scramble, cube, _ := s.RandomMoves(7)
+		want, _ := SolveIDA(cube, DefaultHeuristic)
+		solved, err := SolveKociembaUntil(cube, time.Now().Add(10*time.Second))
+		if err != nil || len(solved) != len(want) || !solved.Apply(cube).IsSolved() {
+			t.Errorf("Solution for %s should be optimal\nGot  %s %v\nWant %s", scramble, solved, err, want)
+		}
+	}
+}
+
+func TestSolveKociembaDeadline(t *testing.T) {
+	_, cube := NewScrambler(3).RandomState()
+	SolveKociemba(cube) // Build the tables first
+	start := time.Now()
+	solved, err := SolveKociembaUntil(cube, start.Add(200*time.Millisecond))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Search should stop after the deadline, took %s", elapsed)
+	}
+	if err != nil || !solved.Apply(cube).IsSolved() {
+		t.Errorf("Wrong solution for %s\nGot  %s %v", cube, solved, err)
+	}
+}
+
+func TestSolveKociembaInvalid(t *testing.T) {
+	cube := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	if _, err := SolveKociemba(cube); !errors.Is(err, ErrInvalidCube) {
+		t.Errorf("Invalid cube should not be solved.\nGot  %v\nWant %s", err, ErrInvalidCube)
+	}
+}
+
+func TestPhase2Coordinates(t *testing.T) {
+	coords := map[string]*coordinate{
+		"corner perm": cornerPerm2Coord,
+		"edge perm":   udEdgePermCoord,
+		"slice perm":  slicePermCoord,
+	}
+	s := NewScrambler(4)
+	for i := 0; i < 50; i++ {
+		scramble, _, _ := s.RandomMoves(20, phase2Moves...)
+		c := cubieEffect(scramble)
+		if twistCoord.get(&c) != 0 || flipCoord.get(&c) != 0 || sliceCoord.get(&c) != 0 {
+			t.Fatalf("Phase 2 moves should stay in G1: %s", scramble)
+		}
+		for j, move := range phase2Moves {
+			next := c.Multiply(cubieMoves[move])
+			for name, coord := range coords {
+				if got, want := coord.move(coord.get(&c), j), coord.get(&next); got != want {
+					t.Fatalf("Wrong %s coordinate after %s:\nGot  %d\nWant %d", name, move, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestIsPhase2Move(t *testing.T) {
+	for _, move := range Moves {
+		want := move[0] == 'U' || move[0] == 'D' || isHalfTurn(move)
+		if got := isPhase2Move(move); got != want {
+			t.Errorf("Wrong phase 2 move %s:\nGot  %v\nWant %v", move, got, want)
+		}
+	}
+}
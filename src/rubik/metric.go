@@ -0,0 +1,95 @@
+package rubik
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Metrics used to measure the length of a move sequence.
+// https://www.speedsolving.com/wiki/index.php/Metric
+
+// Way of counting the moves of a sequence.
+type Metric int
+
+const (
+	HTM Metric = iota // Half Turn Metric: any turn of a face counts as 1
+	QTM               // Quarter Turn Metric: a half turn counts as 2
+	STM               // Slice Turn Metric: any turn of a layer counts as 1
+	ETM               // Execution Turn Metric: any move counts as 1
+)
+
+var Metrics = []Metric{HTM, QTM, STM, ETM}
+
+// String representation.
+func (metric Metric) String() string {
+	switch metric {
+	case HTM:
+		return "HTM"
+	case QTM:
+		return "QTM"
+	case STM:
+		return "STM"
+	case ETM:
+		return "ETM"
+	default:
+		return fmt.Sprintf("Metric(%d)", int(metric))
+	}
+}
+
+// Return `true` if the move is a half turn, such as "U2" or "M2".
+func isHalfTurn(move Move) bool {
+	return strings.HasSuffix(string(move), "2")
+}
+
+// Return `true` if the move turns a middle slice.
+func isSliceTurn(move Move) bool {
+	return len(move) > 0 && strings.IndexByte("MES", move[0]) >= 0
+}
+
+// Return `true` if the move rotates the whole cube.
+func isRotation(move Move) bool {
+	return len(move) > 0 && strings.IndexByte("xyz", move[0]) >= 0
+}
+
+// Cost of a single move in this metric.
+//
+// Rotations only count in ETM. A slice turn counts as two face turns in HTM and QTM,
+// and a wide turn as a single face turn.
+func (metric Metric) Cost(move Move) int {
+	if isRotation(move) {
+		if metric == ETM {
+			return 1
+		}
+		return 0
+	}
+	cost := 1
+	if metric == QTM && isHalfTurn(move) {
+		cost = 2
+	}
+	if (metric == HTM || metric == QTM) && isSliceTurn(move) {
+		cost *= 2
+	}
+	return cost
+}
+
+// Length of the given move sequence in this metric.
+func (metric Metric) Length(moves []Move) int {
+	length := 0
+	for _, move := range moves {
+		length += metric.Cost(move)
+	}
+	return length
+}
+
+// Moves a solver may use to optimize in this metric, each of them costing 1.
+// Rotations are left out since they never bring a cube closer to being solved.
+func (metric Metric) Moves() []Move {
+	switch metric {
+	case QTM:
+		return QuarterTurns
+	case STM, ETM:
+		return append(append(append([]Move{}, Moves...), SliceTurns...), WideTurns...)
+	default:
+		return Moves
+	}
+}
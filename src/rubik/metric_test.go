@@ -0,0 +1,35 @@
+package rubik
+
+import (
+	"testing"
+)
+
+func TestMetricLength(t *testing.T) {
+	moves := []Move{RIGHT, UP_HALF, RIGHT_COUNTER, FRONT_HALF}
+	expected := map[Metric]int{HTM: 4, QTM: 6, STM: 4, ETM: 4}
+	for metric, want := range expected {
+		if got := metric.Length(moves); got != want {
+			t.Errorf("%s length of %s incorrect:\nGot  %d\nWant %d", metric, moves, got, want)
+		}
+	}
+}
+
+func TestMetricLengthExtended(t *testing.T) {
+	moves := []Move{MIDDLE, RIGHT_WIDE, ROTATE_X, UP_HALF, EQUATOR_HALF}
+	expected := map[Metric]int{HTM: 6, QTM: 9, STM: 4, ETM: 5}
+	for metric, want := range expected {
+		if got := metric.Length(moves); got != want {
+			t.Errorf("%s length of %s incorrect:\nGot  %d\nWant %d", metric, moves, got, want)
+		}
+	}
+}
+
+func TestMetricMoves(t *testing.T) {
+	for _, metric := range Metrics {
+		for _, move := range metric.Moves() {
+			if metric.Cost(move) != 1 {
+				t.Errorf("%s move %s should cost 1, got %d", metric, move, metric.Cost(move))
+			}
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package rubik
+
+// Slice turns, wide turns and whole cube rotations.
+//
+// Unlike face turns, these moves also move the centers. A slice turn follows the face
+// it is named after: M like L, E like D and S like F. A wide turn moves a face together
+// with the adjacent slice. A rotation moves the whole cube: x like R, y like U and z like F.
+
+const (
+	MIDDLE           = "M"
+	MIDDLE_COUNTER   = "M'"
+	MIDDLE_HALF      = "M2"
+	EQUATOR          = "E"
+	EQUATOR_COUNTER  = "E'"
+	EQUATOR_HALF     = "E2"
+	STANDING         = "S"
+	STANDING_COUNTER = "S'"
+	STANDING_HALF    = "S2"
+
+	UP_WIDE            = "Uw"
+	UP_WIDE_COUNTER    = "Uw'"
+	UP_WIDE_HALF       = "Uw2"
+	DOWN_WIDE          = "Dw"
+	DOWN_WIDE_COUNTER  = "Dw'"
+	DOWN_WIDE_HALF     = "Dw2"
+	LEFT_WIDE          = "Lw"
+	LEFT_WIDE_COUNTER  = "Lw'"
+	LEFT_WIDE_HALF     = "Lw2"
+	RIGHT_WIDE         = "Rw"
+	RIGHT_WIDE_COUNTER = "Rw'"
+	RIGHT_WIDE_HALF    = "Rw2"
+	FRONT_WIDE         = "Fw"
+	FRONT_WIDE_COUNTER = "Fw'"
+	FRONT_WIDE_HALF    = "Fw2"
+	BACK_WIDE          = "Bw"
+	BACK_WIDE_COUNTER  = "Bw'"
+	BACK_WIDE_HALF     = "Bw2"
+
+	ROTATE_X         = "x"
+	ROTATE_X_COUNTER = "x'"
+	ROTATE_X_HALF    = "x2"
+	ROTATE_Y         = "y"
+	ROTATE_Y_COUNTER = "y'"
+	ROTATE_Y_HALF    = "y2"
+	ROTATE_Z         = "z"
+	ROTATE_Z_COUNTER = "z'"
+	ROTATE_Z_HALF    = "z2"
+)
+
+// Turns of the middle slices.
+var SliceTurns = []Move{
+	MIDDLE, MIDDLE_COUNTER, MIDDLE_HALF,
+	EQUATOR, EQUATOR_COUNTER, EQUATOR_HALF,
+	STANDING, STANDING_COUNTER, STANDING_HALF,
+}
+
+// Turns of a face together with the adjacent slice.
+var WideTurns = []Move{
+	UP_WIDE, UP_WIDE_COUNTER, UP_WIDE_HALF,
+	DOWN_WIDE, DOWN_WIDE_COUNTER, DOWN_WIDE_HALF,
+	LEFT_WIDE, LEFT_WIDE_COUNTER, LEFT_WIDE_HALF,
+	RIGHT_WIDE, RIGHT_WIDE_COUNTER, RIGHT_WIDE_HALF,
+	FRONT_WIDE, FRONT_WIDE_COUNTER, FRONT_WIDE_HALF,
+	BACK_WIDE, BACK_WIDE_COUNTER, BACK_WIDE_HALF,
+}
+
+// Rotations of the whole cube.
+var Rotations = []Move{
+	ROTATE_X, ROTATE_X_COUNTER, ROTATE_X_HALF,
+	ROTATE_Y, ROTATE_Y_COUNTER, ROTATE_Y_HALF,
+	ROTATE_Z, ROTATE_Z_COUNTER, ROTATE_Z_HALF,
+}
+
+// All supported moves: face turns, slice turns, wide turns and rotations.
+var AllMoves = append(append(append(append([]Move{}, Moves...), SliceTurns...), WideTurns...), Rotations...)
+
+// Facelet displacements when no face is rotated.
+var rotateNone = [9]int{0, 1, 2, 3, 4, 5, 6, 7, 8}
+
+// Move Middle slice clockwise (as L), and return a new Cube.
+func (cube Cube) M() Cube {
+	return cube.permute(&permM)
+}
+
+var permM = newPermutation([]*Pair{
+	&Pair{1, 10}, &Pair{4, 13}, &Pair{7, 16},
+	&Pair{10, 46}, &Pair{13, 49}, &Pair{16, 52},
+	&Pair{46, 34}, &Pair{49, 31}, &Pair{52, 28},
+	&Pair{28, 7}, &Pair{31, 4}, &Pair{34, 1},
+}, rotateNone, 0)
+
+// Move Middle slice counter clockwise, and return a new Cube.
+func (cube Cube) Mc() Cube {
+	return cube.permute(&permMc)
+}
+
+var permMc = permM.Then(permM).Then(permM)
+
+// Move Middle slice twice (half turn), and return a new Cube.
+func (cube Cube) M2() Cube {
+	return cube.permute(&permM2)
+}
+
+var permM2 = permM.Then(permM)
+
+// Move Equator slice clockwise (as D), and return a new Cube.
+func (cube Cube) E() Cube {
+	return cube.permute(&permE)
+}
+
+var permE = newPermutation([]*Pair{
+	&Pair{12, 21}, &Pair{13, 22}, &Pair{14, 23},
+	&Pair{21, 30}, &Pair{22, 31}, &Pair{23, 32},
+	&Pair{30, 39}, &Pair{31, 40}, &Pair{32, 41},
+	&Pair{39, 12}, &Pair{40, 13}, &Pair{41, 14},
+}, rotateNone, 0)
+
+// Move Equator slice counter clockwise, and return a new Cube.
+func (cube Cube) Ec() Cube {
+	return cube.permute(&permEc)
+}
+
+var permEc = permE.Then(permE).Then(permE)
+
+// Move Equator slice twice (half turn), and return a new Cube.
+func (cube Cube) E2() Cube {
+	return cube.permute(&permE2)
+}
+
+var permE2 = permE.Then(permE)
+
+// Move Standing slice clockwise (as F), and return a new Cube.
+func (cube Cube) S() Cube {
+	return cube.permute(&permS)
+}
+
+var permS = newPermutation([]*Pair{
+	&Pair{3, 19}, &Pair{4, 22}, &Pair{5, 25},
+	&Pair{19, 50}, &Pair{22, 49}, &Pair{25, 48},
+	&Pair{48, 37}, &Pair{49, 40}, &Pair{50, 43},
+	&Pair{37, 5}, &Pair{40, 4}, &Pair{43, 3},
+}, rotateNone, 0)
+
+// Move Standing slice counter clockwise, and return a new Cube.
+func (cube Cube) Sc() Cube {
+	return cube.permute(&permSc)
+}
+
+var permSc = permS.Then(permS).Then(permS)
+
+// Move Standing slice twice (half turn), and return a new Cube.
+func (cube Cube) S2() Cube {
+	return cube.permute(&permS2)
+}
+
+var permS2 = permS.Then(permS)
+
+// Move Upper face and the adjacent slice clockwise, and return a new Cube.
+func (cube Cube) Uw() Cube {
+	return cube.permute(&permUw)
+}
+
+var permUw = permU.Then(permEc)
+
+// Move Upper face and the adjacent slice counter clockwise, and return a new Cube.
+func (cube Cube) Uwc() Cube {
+	return cube.permute(&permUwc)
+}
+
+var permUwc = permUc.Then(permE)
+
+// Move Upper face and the adjacent slice twice (half turn), and return a new Cube.
+func (cube Cube) Uw2() Cube {
+	return cube.permute(&permUw2)
+}
+
+var permUw2 = permUw.Then(permUw)
+
+// Move Down face and the adjacent slice clockwise, and return a new Cube.
+func (cube Cube) Dw() Cube {
+	return cube.permute(&permDw)
+}
+
+var permDw = permD.Then(permE)
+
+// Move Down face and the adjacent slice counter clockwise, and return a new Cube.
+func (cube Cube) Dwc() Cube {
+	return cube.permute(&permDwc)
+}
+
+var permDwc = permDc.Then(permEc)
+
+// Move Down face and the adjacent slice twice (half turn), and return a new Cube.
+func (cube Cube) Dw2() Cube {
+	return cube.permute(&permDw2)
+}
+
+var permDw2 = permDw.Then(permDw)
+
+// Move Left face and the adjacent slice clockwise, and return a new Cube.
+func (cube Cube) Lw() Cube {
+	return cube.permute(&permLw)
+}
+
+var permLw = permL.Then(permM)
+
+// Move Left face and the adjacent slice counter clockwise, and return a new Cube.
+func (cube Cube) Lwc() Cube {
+	return cube.permute(&permLwc)
+}
+
+var permLwc = permLc.Then(permMc)
+
+// Move Left face and the adjacent slice twice (half turn), and return a new Cube.
+func (cube Cube) Lw2() Cube {
+	return cube.permute(&permLw2)
+}
+
+var permLw2 = permLw.Then(permLw)
+
+// Move Right face and the adjacent slice clockwise, and return a new Cube.
+func (cube Cube) Rw() Cube {
+	return cube.permute(&permRw)
+}
+
+var permRw = permR.Then(permMc)
+
+// Move Right face and the adjacent slice counter clockwise, and return a new Cube.
+func (cube Cube) Rwc() Cube {
+	return cube.permute(&permRwc)
+}
+
+var permRwc = permRc.Then(permM)
+
+// Move Right face and the adjacent slice twice (half turn), and return a new Cube.
+func (cube Cube) Rw2() Cube {
+	return cube.permute(&permRw2)
+}
+
+var permRw2 = permRw.Then(permRw)
+
+// Move Front face and the adjacent slice clockwise, and return a new Cube.
+func (cube Cube) Fw() Cube {
+	return cube.permute(&permFw)
+}
+
+var permFw = permF.Then(permS)
+
+// Move Front face and the adjacent slice counter clockwise, and return a new Cube.
+func (cube Cube) Fwc() Cube {
+	return cube.permute(&permFwc)
+}
+
+var permFwc = permFc.Then(permSc)
+
+// Move Front face and the adjacent slice twice (half turn), and return a new Cube.
+func (cube Cube) Fw2() Cube {
+	return cube.permute(&permFw2)
+}
+
+var permFw2 = permFw.Then(permFw)
+
+// Move Back face and the adjacent slice clockwise, and return a new Cube.
+func (cube Cube) Bw() Cube {
+	return cube.permute(&permBw)
+}
+
+var permBw = permB.Then(permSc)
+
+// Move Back face and the adjacent slice counter clockwise, and return a new Cube.
+func (cube Cube) Bwc() Cube {
+	return cube.permute(&permBwc)
+}
+
+var permBwc = permBc.Then(permS)
+
+// Move Back face and the adjacent slice twice (half turn), and return a new Cube.
+func (cube Cube) Bw2() Cube {
+	return cube.permute(&permBw2)
+}
+
+var permBw2 = permBw.Then(permBw)
+
+// Rotate the whole cube clockwise (as R), and return a new Cube.
+func (cube Cube) X() Cube {
+	return cube.permute(&permX)
+}
+
+var permX = permR.Then(permMc).Then(permLc)
+
+// Rotate the whole cube counter clockwise, and return a new Cube.
+func (cube Cube) Xc() Cube {
+	return cube.permute(&permXc)
+}
+
+var permXc = permX.Then(permX).Then(permX)
+
+// Rotate the whole cube twice, and return a new Cube.
+func (cube Cube) X2() Cube {
+	return cube.permute(&permX2)
+}
+
+var permX2 = permX.Then(permX)
+
+// Rotate the whole cube clockwise (as U), and return a new Cube.
+func (cube Cube) Y() Cube {
+	return cube.permute(&permY)
+}
+
+var permY = permU.Then(permEc).Then(permDc)
+
+// Rotate the whole cube counter clockwise, and return a new Cube.
+func (cube Cube) Yc() Cube {
+	return cube.permute(&permYc)
+}
+
+var permYc = permY.Then(permY).Then(permY)
+
+// Rotate the whole cube twice, and return a new Cube.
+func (cube Cube) Y2() Cube {
+	return cube.permute(&permY2)
+}
+
+var permY2 = permY.Then(permY)
+
+// Rotate the whole cube clockwise (as F), and return a new Cube.
+func (cube Cube) Z() Cube {
+	return cube.permute(&permZ)
+}
+
+var permZ = permF.Then(permS).Then(permBc)
+
+// Rotate the whole cube counter clockwise, and return a new Cube.
+func (cube Cube) Zc() Cube {
+	return cube.permute(&permZc)
+}
+
+var permZc = permZ.Then(permZ).Then(permZ)
+
+// Rotate the whole cube twice, and return a new Cube.
+func (cube Cube) Z2() Cube {
+	return cube.permute(&permZ2)
+}
+
+var permZ2 = permZ.Then(permZ)
+
+func init() {
+	permutations := []*Permutation{
+		&permM, &permMc, &permM2, &permE, &permEc, &permE2, &permS, &permSc, &permS2,
+		&permUw, &permUwc, &permUw2, &permDw, &permDwc, &permDw2,
+		&permLw, &permLwc, &permLw2, &permRw, &permRwc, &permRw2,
+		&permFw, &permFwc, &permFw2, &permBw, &permBwc, &permBw2,
+		&permX, &permXc, &permX2, &permY, &permYc, &permY2, &permZ, &permZc, &permZ2,
+	}
+	moves := append(append(append([]Move{}, SliceTurns...), WideTurns...), Rotations...)
+	for i, move := range moves {
+		movePermutations[move] = permutations[i]
+	}
+
+	orientations = []Permutation{identity()}
+	for i := 0; i < len(orientations); i++ {
+		for _, r := range []*Permutation{&permX, &permY} {
+			next := orientations[i].Then(*r)
+			known := false
+			for _, o := range orientations {
+				known = known || o == next
+			}
+			if !known {
+				orientations = append(orientations, next)
+			}
+		}
+	}
+}
+
+// The 24 facelet permutations of the whole cube rotations, starting with the identity.
+var orientations []Permutation
+
+// Check if two cubes are equal, once one of them is rotated to the orientation of the other.
+func (cube Cube) EqualsUpToRotation(other Cube) bool {
+	for i := range orientations {
+		if cube.permute(&orientations[i]) == other {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package rubik
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Parallel searches.
+//
+// Searches given more than one worker in their options spread over several goroutines, and
+// still return the same solution as the sequential search:
+//   - IDA* splits each iteration into the subtrees IDA_SPLIT_DEPTH moves below the cube. Idle
+//     workers take the next subtree from a shared queue, so that no worker waits while others
+//     explore large subtrees. When a solution is found, the subtrees after it are abandoned,
+//     and those before it are still explored: the first solution in the sequential order wins.
+//   - BFS expands each layer by chunks of BFS_CHUNK_SIZE cubes, taken by idle workers from
+//     a shared queue. Workers do not change the tree: their children are added in order once
+//     a round of chunks is expanded, so that the tree is the same as with a sequential BFS.
+//
+// Workers count their cubes in the budget of the search every CANCEL_CHECK_INTERVAL cubes:
+// a search may explore a few more cubes than its limit before stopping.
+
+// Number of moves leading to each subtree explored by a worker of a parallel IDA*.
+const IDA_SPLIT_DEPTH = 3
+
+// Number of cubes of a layer expanded by a worker of a parallel BFS at once.
+const BFS_CHUNK_SIZE = 1024
+
+// Chunks expanded by each worker of a parallel BFS before their children are added.
+const BFS_CHUNKS_PER_WORKER = 4
+
+// Subtree of an IDA* search, explored by a worker.
+type idaTask struct {
+	path  Algorithm // Moves leading to the subtree
+	cube  CubieCube // Cube at the root of the subtree
+	bound int       // Moves left
+}
+
+// Like search, but with the given number of workers. Return the moves of the solution.
+func (s *idaSearch) searchParallel(c CubieCube, bound, workers int) (Algorithm, bool) {
+	tasks := s.split(c, bound, nil)
+	if s.budget.err != nil {
+		return nil, false
+	}
+	var (
+		lock     sync.Mutex
+		best     = len(tasks) // Index of the first task known to hold a solution
+		solution Algorithm
+		cancels  = make([]context.CancelFunc, len(tasks))
+		next     atomic.Int64
+		wg       sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < len(tasks); i = int(next.Add(1) - 1) {
+				lock.Lock()
+				if i > best {
+					lock.Unlock()
+					return
+				}
+				ctx, cancel := context.WithCancel(s.budget.ctx)
+				cancels[i] = cancel
+				lock.Unlock()
+
+				task := tasks[i]
+				worker := &idaSearch{s.moves, s.quarterTurns, s.goal, s.heuristic, task.path, s.budget.fork(ctx)}
+				found := worker.search(task.cube, task.bound)
+				stopped := !worker.budget.sync()
+
+				lock.Lock()
+				if found && i < best {
+					best, solution = i, worker.path
+					for _, cancel := range cancels[i+1:] {
+						if cancel != nil {
+							cancel()
+						}
+					}
+				}
+				cancel()
+				lock.Unlock()
+				if stopped {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if s.budget.err != nil || best == len(tasks) {
+		return nil, false
+	}
+	return solution, true
+}
+
+// Append to the tasks the subtrees of the search of the given bound, IDA_SPLIT_DEPTH moves
+// below the cube, in the order they are explored by the sequential search.
+func (s *idaSearch) split(c CubieCube, bound int, tasks []idaTask) []idaTask {
+	if s.isReached(c) || len(s.path) == IDA_SPLIT_DEPTH {
+		return append(tasks, idaTask{append(Algorithm{}, s.path...), c, bound})
+	}
+	if bound == 0 || !s.budget.visit() || s.heuristic.Estimate(c) > bound {
+		return tasks
+	}
+	for _, move := range s.moves {
+		if isRedundant(s.path, move, s.quarterTurns) {
+			continue
+		}
+		s.path = append(s.path, move)
+		tasks = s.split(c.Multiply(cubieMoves[move]), bound-1, tasks)
+		s.path = s.path[:len(s.path)-1]
+		if s.budget.err != nil {
+			return tasks
+		}
+	}
+	return tasks
+}
+
+// Cube reached by a worker expanding a layer of a BFS, not visited yet.
+type bfsChild struct {
+	key    cubeKey
+	parent int32
+	move   uint8
+	goal   bool // The cube reaches the goal
+}
+
+// Like expand, but with the workers of the tree. The goal is called by several goroutines.
+func (t *bfsTree) expandParallel(start int, budget *searchBudget, goal func(key cubeKey, cube Cube) bool) (int, error) {
+	end := len(t.nodes)
+	chunks := make([][]bfsChild, t.workers*BFS_CHUNKS_PER_WORKER)
+	for first := start; first < end; first += len(chunks) * BFS_CHUNK_SIZE {
+		var next atomic.Int64
+		var wg sync.WaitGroup
+		for w := 0; w < t.workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				worker := budget.fork(budget.ctx)
+				defer worker.sync()
+				for k := int(next.Add(1) - 1); k < len(chunks) && worker.err == nil; k = int(next.Add(1) - 1) {
+					from := first + k*BFS_CHUNK_SIZE
+					chunks[k] = t.children(from, min(from+BFS_CHUNK_SIZE, end), worker, goal, chunks[k][:0])
+				}
+			}()
+		}
+		wg.Wait()
+		if budget.err != nil {
+			return -1, budget.err
+		}
+		for _, children := range chunks {
+			for _, child := range children {
+				if !t.add(child.key, int(child.parent), int(child.move)) {
+					continue
+				}
+				if child.goal {
+					return len(t.nodes) - 1, nil
+				}
+				if !budget.allocate(BFS_NODE_SIZE) {
+					return -1, budget.err
+				}
+			}
+		}
+	}
+	return -1, nil
+}
+
+// Append to the children the cubes reached from the nodes from `from` to `to`, that are not
+// visited yet. Neither the tree nor the budget of the search is changed.
+func (t *bfsTree) children(from, to int, budget *searchBudget, goal func(key cubeKey, cube Cube) bool, children []bfsChild) []bfsChild {
+	for i := from; i < to; i++ {
+		c := t.cube(i)
+		for j := range t.moves {
+			if t.isRedundant(i, j) {
+				continue
+			}
+			if !budget.visit() {
+				return children
+			}
+			next := c.permute(t.permutations[j])
+			key := t.key(next)
+			if _, found := t.visited[key]; !found {
+				children = append(children, bfsChild{key, int32(i), uint8(j), goal(key, next)})
+			}
+		}
+	}
+	return children
+}
@@ -0,0 +1,129 @@
+package rubik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+func TestSolveParallel(t *testing.T) {
+	s := NewScrambler(1)
+	testCases := []struct {
+		Solver string
+		Metric Metric
+		Length int
+	}{
+		{"ida", HTM, 0},
+		{"ida", HTM, 1},
+		{"ida", HTM, 9},
+		{"ida", QTM, 8},
+		{"bfs", HTM, 5},
+		{"bfs", STM, 5},
+		{"bidirectional", HTM, 8},
+		{"bidirectional", ETM, 7},
+	}
+	for _, tc := range testCases {
+		solver, _ := LookupSolver(tc.Solver)
+		scramble, cube, _ := s.RandomMoves(tc.Length, tc.Metric.Moves()...)
+		want, err := solver.Solve(context.Background(), cube, Options{Metric: tc.Metric})
+		if err != nil {
+			t.Fatalf("Sequential %s failed on %s: %s", tc.Solver, scramble, err)
+		}
+		for _, workers := range []int{2, 3, 8} {
+			got, err := solver.Solve(context.Background(), cube, Options{Metric: tc.Metric, Workers: workers})
+			if err != nil || got.String() != want.String() {
+				t.Errorf("Parallel %s with %d workers should find the same solution for %s:\nGot  %s %v\nWant %s", tc.Solver, workers, scramble, got, err, want)
+			}
+		}
+	}
+}
+
+func TestSolveParallelLimits(t *testing.T) {
+	cube := MustParseAlgorithm("R U F' L2 D B").Apply(NewSolvedCube())
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	testCases := []struct {
+		Solver string
+		Ctx    context.Context
+		Opts   Options
+		Want   error
+	}{
+		{"ida", context.Background(), Options{MaxDepth: 5}, ErrDepthLimit},
+		{"ida", context.Background(), Options{MaxNodes: 10}, ErrNodeLimit},
+		{"ida", canceled, Options{}, ErrCanceled},
+		{"bfs", context.Background(), Options{MaxDepth: 5}, ErrDepthLimit},
+		{"bfs", context.Background(), Options{MaxNodes: 5000}, ErrNodeLimit},
+		{"bfs", context.Background(), Options{MaxMemory: 100000}, ErrMemoryLimit},
+		{"bfs", canceled, Options{}, ErrCanceled},
+		{"bidirectional", context.Background(), Options{MaxNodes: 5000}, ErrNodeLimit},
+	}
+	for _, tc := range testCases {
+		solver, _ := LookupSolver(tc.Solver)
+		tc.Opts.Workers = 4
+		if _, err := solver.Solve(tc.Ctx, cube, tc.Opts); !errors.Is(err, tc.Want) {
+			t.Errorf("Parallel %s should stop:\nGot  %v\nWant %s", tc.Solver, err, tc.Want)
+		}
+	}
+}
+
+func TestSearchBudgetFork(t *testing.T) {
+	budget := &searchBudget{ctx: context.Background(), maxNodes: 3000}
+	ctx, cancel := context.WithCancel(context.Background())
+	worker := budget.fork(ctx)
+	for i := 0; i < 2000; i++ {
+		if !worker.visit() {
+			t.Fatalf("Worker should not stop after %d cubes", i)
+		}
+	}
+	if budget.nodes != CANCEL_CHECK_INTERVAL {
+		t.Errorf("Wrong number of cubes counted in the budget of the search:\nGot  %d\nWant %d", budget.nodes, CANCEL_CHECK_INTERVAL)
+	}
+	cancel()
+	if worker.sync() || !errors.Is(worker.err, ErrCanceled) || budget.err != nil || budget.nodes != 2000 {
+		t.Errorf("Canceled worker should stop alone:\nGot  %v, %d cubes\nWant %s", worker.err, budget.nodes, ErrCanceled)
+	}
+	other := budget.fork(context.Background())
+	for other.visit() {
+	}
+	if !errors.Is(budget.err, ErrNodeLimit) || !errors.Is(other.err, ErrNodeLimit) {
+		t.Errorf("Search should stop after the maximum number of cubes:\nGot  %v\nWant %s", budget.err, ErrNodeLimit)
+	}
+}
+
+func BenchmarkSolveParallel(b *testing.B) {
+	benchmarks := []struct {
+		Solver   string
+		Metric   Metric
+		Scramble string
+	}{
+		{"ida", HTM, "R U F' L2 D B R' U2 F D' L"},
+		{"bfs", STM, "R M U' E2"},
+		{"bidirectional", STM, "R M U' E2 F S' L"},
+	}
+	for _, bm := range benchmarks {
+		solver, err := LookupSolver(bm.Solver)
+		if err != nil {
+			b.Fatal(err)
+		}
+		cube := MustParseAlgorithm(bm.Scramble).Apply(NewSolvedCube())
+		// Warm up, so that the tables of the solver are not built in the first run
+		if _, err := solver.Solve(context.Background(), cube, Options{Metric: bm.Metric}); err != nil {
+			b.Fatal(err)
+		}
+		for _, workers := range []int{1, 2, 4, 8, 16} {
+			// Compare a few workers even on small machines, more only if they can run in parallel
+			if workers > 4 && workers > runtime.NumCPU() {
+				break
+			}
+			b.Run(fmt.Sprintf("%s/%d", bm.Solver, workers), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := solver.Solve(context.Background(), cube, Options{Metric: bm.Metric, Workers: workers}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
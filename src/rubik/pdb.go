@@ -0,0 +1,537 @@
+package rubik
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Pattern databases, as used by Korf to find optimal solutions.
+//
+// A pattern database gives the exact number of moves needed to solve some of the pieces, for
+// every position and orientation of these pieces, whatever happens to the other ones. It is
+// therefore an admissible heuristic for IDA*. Databases are built with a BFS from the solved
+// cube, using the face moves of cube.go, and store 4 bits per entry. They can be written to
+// files and loaded back, memory-mapped when the platform supports it.
+// https://www.cs.princeton.edu/courses/archive/fall06/cos402/papers/korfrubik.pdf
+
+// Version of the pattern database file format.
+const PATTERN_DATABASE_VERSION = 1
+
+// Magic number starting pattern database files.
+const PATTERN_DATABASE_MAGIC = "RPDB"
+
+// Size of the header of pattern database files, in bytes.
+//
+//	0  magic          4 bytes
+//	4  version        uint32
+//	8  corner count   uint8
+//	9  edge count     uint8
+//	10 corners        8 bytes, unused ones are 0
+//	18 edges          12 bytes, unused ones are 0
+//	30 reserved       2 bytes
+//	32 data length    uint64
+//	40 checksum       uint32, CRC-32 (IEEE) of the first 40 bytes followed by the data
+//	44 reserved       4 bytes
+//
+// Numbers are little-endian. The data follows the header, two entries per byte, the entry
+// with the lowest index in the lowest 4 bits.
+const PATTERN_DATABASE_HEADER_SIZE = 48
+
+// Largest number of entries of a pattern database, taking 512 MB of memory. Korf's databases
+// have less than 100 million entries, and a database of 7 edges about 500 million.
+const MAX_PATTERN_SIZE = 1 << 30
+
+// Entry of the database for arrangements that were not reached.
+const unknownDistance = 15
+
+// Pieces whose position and orientation are tracked by a pattern database.
+type Pattern struct {
+	Corners []Corner
+	Edges   []Edge
+}
+
+// Patterns of Korf's pattern databases: all the corners, and two halves of the edges.
+var (
+	CornersPattern    = Pattern{Corners: []Corner{URF, UFL, ULB, UBR, DFR, DLF, DBL, DRB}}
+	FirstEdgesPattern = Pattern{Edges: []Edge{UR, UF, UL, UB, DR, DF}}
+	LastEdgesPattern  = Pattern{Edges: []Edge{DL, DB, FR, FL, BL, BR}}
+)
+
+// File of a pattern database in a directory.
+type PatternFile struct {
+	Name    string
+	Pattern Pattern
+}
+
+// Files of Korf's pattern databases, as loaded by LoadKorfPatternDatabases.
+var KorfPatternFiles = []PatternFile{
+	{"corners.pdb", CornersPattern},
+	{"edges1.pdb", FirstEdgesPattern},
+	{"edges2.pdb", LastEdgesPattern},
+}
+
+// Number of arrangements of the pieces of the pattern, aka entries of its database.
+// Return 0 if the pattern has more corners or edges than the cube, or too many
+// arrangements to be counted by an int.
+func (p Pattern) Size() int {
+	if len(p.Corners) > 8 || len(p.Edges) > 12 {
+		return 0
+	}
+	pieces := p.pieces()
+	corners, edges := pieces.corners.size(), pieces.edges.size()
+	if edges > math.MaxInt/corners {
+		return 0
+	}
+	return corners * edges
+}
+
+// Return `nil` if the pattern lists at least one piece, each piece at most once, with at
+// most MAX_PATTERN_SIZE arrangements, or ErrInvalidPattern otherwise.
+func (p Pattern) Validate() error {
+	if len(p.Corners)+len(p.Edges) == 0 || len(p.Corners) > 8 || len(p.Edges) > 12 {
+		return ErrInvalidPattern
+	}
+	if pieces := p.pieces(); !pieces.corners.valid() || !pieces.edges.valid() {
+		return ErrInvalidPattern
+	}
+	if size := p.Size(); size == 0 || size > MAX_PATTERN_SIZE {
+		return ErrInvalidPattern
+	}
+	return nil
+}
+
+// Corners and edges of the pattern, ready to compute indices.
+func (p Pattern) pieces() patternIndexer {
+	corners := patternPieces{positions: 8, orientations: 3, count: len(p.Corners)}
+	for i, corner := range p.Corners {
+		corners.pieces[i] = int(corner)
+	}
+	edges := patternPieces{positions: 12, orientations: 2, count: len(p.Edges)}
+	for i, edge := range p.Edges {
+		edges.pieces[i] = int(edge)
+	}
+	return patternIndexer{corners, edges}
+}
+
+// Index of the arrangement of the pieces of the pattern in the cube, from 0 to Size()-1.
+func (p Pattern) index(c *CubieCube) int {
+	return p.pieces().index(c)
+}
+
+// Build a cube whose pieces of the pattern are arranged as given by the index.
+func (p Pattern) cube(index int) CubieCube {
+	return p.pieces().cube(index)
+}
+
+// Corners and edges of a pattern.
+type patternIndexer struct {
+	corners, edges patternPieces
+}
+
+// Number of arrangements of the pieces.
+func (p patternIndexer) size() int {
+	return p.corners.size() * p.edges.size()
+}
+
+// Index of the arrangement of the pieces in the cube, the corners varying the slowest.
+func (p patternIndexer) index(c *CubieCube) int {
+	var cp, co, ep, eo [12]int
+	for i := range c.Cp {
+		cp[i], co[i] = int(c.Cp[i]), c.Co[i]
+	}
+	for i := range c.Ep {
+		ep[i], eo[i] = int(c.Ep[i]), c.Eo[i]
+	}
+	return p.corners.index(&cp, &co)*p.edges.size() + p.edges.index(&ep, &eo)
+}
+
+// Build a cube whose pieces are arranged as given by the index.
+// The other pieces fill the remaining positions, in an arbitrary way.
+func (p patternIndexer) cube(index int) CubieCube {
+	c := NewSolvedCubieCube()
+	var cp, co, ep, eo [12]int
+	p.corners.arrange(index/p.edges.size(), &cp, &co)
+	p.edges.arrange(index%p.edges.size(), &ep, &eo)
+	for i := range c.Cp {
+		c.Cp[i], c.Co[i] = Corner(cp[i]), co[i]
+	}
+	for i := range c.Ep {
+		c.Ep[i], c.Eo[i] = Edge(ep[i]), eo[i]
+	}
+	return c
+}
+
+// Corners or edges of a pattern.
+type patternPieces struct {
+	positions    int // Number of corner or edge positions
+	orientations int // Number of orientations of a corner or an edge
+	pieces       [12]int
+	count        int // Number of pieces in the pattern
+}
+
+// Return `true` if the pieces exist and are all different.
+func (p patternPieces) valid() bool {
+	seen := [12]bool{}
+	for _, piece := range p.pieces[:p.count] {
+		if piece < 0 || piece >= p.positions || seen[piece] {
+			return false
+		}
+		seen[piece] = true
+	}
+	return true
+}
+
+// Number of pieces whose orientation is part of the index. When all the pieces are part
+// of the pattern, the orientation of the last one is given by the others.
+func (p patternPieces) oriented() int {
+	if p.count == p.positions {
+		return p.count - 1
+	}
+	return p.count
+}
+
+// Number of arrangements of the pieces.
+func (p patternPieces) size() int {
+	size := 1
+	for i := 0; i < p.count; i++ {
+		size *= p.positions - i
+	}
+	for i := 0; i < p.oriented(); i++ {
+		size *= p.orientations
+	}
+	return size
+}
+
+// Index of the arrangement of the pieces, given the piece found at each position
+// and its orientation: the rank of the positions of the pieces, then their orientations.
+func (p patternPieces) index(perm, orientations *[12]int) int {
+	var where, positions [12]int
+	for position, piece := range perm[:p.positions] {
+		where[piece] = position
+	}
+	index := 0
+	for i, piece := range p.pieces[:p.count] {
+		position := where[piece]
+		positions[i] = position
+		smaller := 0
+		for _, other := range positions[:i] {
+			if other < position {
+				smaller++
+			}
+		}
+		index = index*(p.positions-i) + position - smaller
+	}
+	for _, position := range positions[:p.oriented()] {
+		index = index*p.orientations + orientations[position]
+	}
+	return index
+}
+
+// Fill the piece found at each position and its orientation, for the arrangement with
+// the given index. Positions left by the pieces get the other pieces, in order.
+func (p patternPieces) arrange(index int, perm, orientations *[12]int) {
+	var orientation, digits [12]int
+	total := 0
+	for i := p.oriented() - 1; i >= 0; i-- {
+		orientation[i] = index % p.orientations
+		total += orientation[i]
+		index /= p.orientations
+	}
+	if p.oriented() < p.count {
+		orientation[p.count-1] = (p.orientations - total%p.orientations) % p.orientations
+	}
+	for i := p.count - 1; i >= 0; i-- {
+		digits[i] = index % (p.positions - i)
+		index /= p.positions - i
+	}
+
+	var used, isPatternPiece [12]bool
+	for i, digit := range digits[:p.count] {
+		// Position of the piece: the digit-th position not used yet
+		position := 0
+		for ; used[position] || digit > 0; position++ {
+			if !used[position] {
+				digit--
+			}
+		}
+		used[position] = true
+		perm[position], orientations[position] = p.pieces[i], orientation[i]
+		isPatternPiece[p.pieces[i]] = true
+	}
+	position := 0
+	for piece := 0; piece < p.positions; piece++ {
+		if isPatternPiece[piece] {
+			continue
+		}
+		for used[position] {
+			position++
+		}
+		used[position] = true
+		perm[position], orientations[position] = piece, 0
+	}
+}
+
+// Exact number of moves needed to solve the pieces of a pattern, for each of their arrangements.
+type PatternDatabase struct {
+	Pattern Pattern
+
+	pieces patternIndexer
+	data   []byte       // Two entries per byte, as in files
+	unmap  func() error // Release the memory-mapped file, if any
+}
+
+// Build the pattern database of the given pattern. Korf's databases take a few minutes each.
+//
+// Return ErrInvalidPattern if the pattern is not valid.
+func NewPatternDatabase(pattern Pattern) (*PatternDatabase, error) {
+	if err := pattern.Validate(); err != nil {
+		return nil, err
+	}
+	pieces := pattern.pieces()
+	db := &PatternDatabase{Pattern: pattern, pieces: pieces, data: make([]byte, (pieces.size()+1)/2)}
+	for i := range db.data {
+		db.data[i] = 0xff
+	}
+	moves := make([]CubieCube, len(Moves))
+	for i, move := range Moves {
+		moves[i], _ = NewSolvedCube().MustTurn(move).ToCubie()
+	}
+
+	// BFS, one depth at a time. Entries of the current depth are found by scanning the whole
+	// database, so that no queue is needed. Once fewer entries are left unknown than found at
+	// the current depth, the unknown ones are checked instead for a neighbor at this depth.
+	solved := NewSolvedCubieCube()
+	db.set(pieces.index(&solved), 0)
+	for depth, count, unknown := 0, 1, pieces.size()-1; count > 0; depth++ {
+		backward := unknown < count
+		count = 0
+		for index := 0; index < pieces.size(); index++ {
+			if backward && db.at(index) == unknownDistance {
+				c := pieces.cube(index)
+				for _, move := range moves {
+					next := c.Multiply(move)
+					if db.at(pieces.index(&next)) == depth {
+						db.set(index, depth+1)
+						count++
+						break
+					}
+				}
+			} else if !backward && db.at(index) == depth {
+				c := pieces.cube(index)
+				for _, move := range moves {
+					next := c.Multiply(move)
+					if i := pieces.index(&next); db.at(i) == unknownDistance {
+						db.set(i, depth+1)
+						count++
+					}
+				}
+			}
+		}
+		unknown -= count
+	}
+	return db, nil
+}
+
+// Exact number of moves needed to solve the pieces of the pattern in the cube.
+func (db *PatternDatabase) Distance(c CubieCube) int {
+	return db.at(db.pieces.index(&c))
+}
+
+// Lower bound of the number of moves needed to solve the cube, to be used as a Heuristic.
+func (db *PatternDatabase) Estimate(c CubieCube) int {
+	return db.Distance(c)
+}
+
+// Entry at the given index.
+func (db *PatternDatabase) at(index int) int {
+	return int(db.data[index/2]>>(4*(index%2))) & 0xf
+}
+
+// Set the entry at the given index.
+func (db *PatternDatabase) set(index, distance int) {
+	shift := 4 * (index % 2)
+	db.data[index/2] = db.data[index/2]&^(0xf<<shift) | byte(distance)<<shift
+}
+
+// Write the database in the pattern database file format.
+func (db *PatternDatabase) WriteTo(w io.Writer) (int64, error) {
+	header := db.header()
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := w.Write(db.data)
+	return int64(n + m), err
+}
+
+// Write the database to a file, replacing it if it exists.
+func (db *PatternDatabase) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := db.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Header of the database file, see PATTERN_DATABASE_HEADER_SIZE.
+func (db *PatternDatabase) header() []byte {
+	header := make([]byte, PATTERN_DATABASE_HEADER_SIZE)
+	copy(header, PATTERN_DATABASE_MAGIC)
+	binary.LittleEndian.PutUint32(header[4:], PATTERN_DATABASE_VERSION)
+	header[8], header[9] = byte(len(db.Pattern.Corners)), byte(len(db.Pattern.Edges))
+	for i, corner := range db.Pattern.Corners {
+		header[10+i] = byte(corner)
+	}
+	for i, edge := range db.Pattern.Edges {
+		header[18+i] = byte(edge)
+	}
+	binary.LittleEndian.PutUint64(header[32:], uint64(len(db.data)))
+	checksum := crc32.Update(crc32.ChecksumIEEE(header[:40]), crc32.IEEETable, db.data)
+	binary.LittleEndian.PutUint32(header[40:], checksum)
+	return header
+}
+
+// Load a pattern database from a file written by Save, memory-mapping it when possible.
+// The database must be closed after use.
+//
+// Return ErrInvalidPatternDatabase if the file is not a pattern database,
+// ErrPatternDatabaseVersion if its format is not supported, or ErrPatternDatabaseChecksum
+// if it is corrupted.
+func LoadPatternDatabase(path string) (*PatternDatabase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < PATTERN_DATABASE_HEADER_SIZE {
+		return nil, ErrInvalidPatternDatabase
+	}
+	content, unmap, err := mapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+	db, err := parsePatternDatabase(content)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	db.unmap = unmap
+	return db, nil
+}
+
+// Read a pattern database from the content of its file, without copying the data.
+func parsePatternDatabase(content []byte) (*PatternDatabase, error) {
+	header, data := content[:PATTERN_DATABASE_HEADER_SIZE], content[PATTERN_DATABASE_HEADER_SIZE:]
+	if string(header[:4]) != PATTERN_DATABASE_MAGIC {
+		return nil, ErrInvalidPatternDatabase
+	}
+	if binary.LittleEndian.Uint32(header[4:]) != PATTERN_DATABASE_VERSION {
+		return nil, ErrPatternDatabaseVersion
+	}
+	corners, edges := int(header[8]), int(header[9])
+	if corners > 8 || edges > 12 {
+		return nil, ErrInvalidPatternDatabase
+	}
+	pattern := Pattern{Corners: make([]Corner, corners), Edges: make([]Edge, edges)}
+	for i := range pattern.Corners {
+		pattern.Corners[i] = Corner(header[10+i])
+	}
+	for i := range pattern.Edges {
+		pattern.Edges[i] = Edge(header[18+i])
+	}
+	if pattern.Validate() != nil ||
+		binary.LittleEndian.Uint64(header[32:]) != uint64(len(data)) ||
+		len(data) != (pattern.Size()+1)/2 {
+		return nil, ErrInvalidPatternDatabase
+	}
+	checksum := crc32.Update(crc32.ChecksumIEEE(header[:40]), crc32.IEEETable, data)
+	if binary.LittleEndian.Uint32(header[40:]) != checksum {
+		return nil, ErrPatternDatabaseChecksum
+	}
+	return &PatternDatabase{Pattern: pattern, pieces: pattern.pieces(), data: data}, nil
+}
+
+// Release the memory used by a database loaded from a file. The database must not be used
+// afterwards. Closing a database built by NewPatternDatabase does nothing.
+func (db *PatternDatabase) Close() error {
+	if db.unmap == nil {
+		return nil
+	}
+	unmap := db.unmap
+	db.unmap, db.data = nil, nil
+	return unmap()
+}
+
+// Pattern databases used together as a Heuristic, estimating the largest of their distances.
+type PatternDatabases []*PatternDatabase
+
+// Lower bound of the number of moves needed to solve the cube, to be used as a Heuristic.
+func (dbs PatternDatabases) Estimate(c CubieCube) int {
+	estimate := 0
+	for _, db := range dbs {
+		if e := db.Distance(c); e > estimate {
+			estimate = e
+		}
+	}
+	return estimate
+}
+
+// Close all the databases, and return the first error.
+func (dbs PatternDatabases) Close() error {
+	var first error
+	for _, db := range dbs {
+		if err := db.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Load the pattern databases of the given files, as LoadPatternDatabase does. The databases
+// must be closed after use.
+//
+// Return the error of the first file that could not be loaded, after closing the others.
+func LoadPatternDatabases(paths ...string) (PatternDatabases, error) {
+	dbs := PatternDatabases{}
+	for _, path := range paths {
+		db, err := LoadPatternDatabase(path)
+		var pathErr *os.PathError
+		if err != nil && !errors.As(err, &pathErr) {
+			err = fmt.Errorf("%s: %w", path, err)
+		}
+		if err != nil {
+			dbs.Close()
+			return nil, err
+		}
+		dbs = append(dbs, db)
+	}
+	return dbs, nil
+}
+
+// Load Korf's pattern databases from the files of KorfPatternFiles in the directory, as
+// written by the pdb command, to be used as the heuristic of optimal searches:
+//
+//	dbs, err := LoadKorfPatternDatabases(dir)
+//	defer dbs.Close()
+//	solved, err := SolveContext(ctx, cube, Options{Heuristic: dbs})
+func LoadKorfPatternDatabases(dir string) (PatternDatabases, error) {
+	paths := make([]string, len(KorfPatternFiles))
+	for i, file := range KorfPatternFiles {
+		paths[i] = filepath.Join(dir, file.Name)
+	}
+	return LoadPatternDatabases(paths...)
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package rubik
+
+import (
+	"os"
+	"syscall"
+)
+
+// Map the content of the file in memory, read-only. Return the function releasing it.
+func mapFile(f *os.File, size int) ([]byte, func() error, error) {
+	content, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, func() error { return syscall.Munmap(content) }, nil
+}
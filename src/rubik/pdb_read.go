@@ -0,0 +1,17 @@
+//go:build !unix
+
+package rubik
+
+import (
+	"io"
+	"os"
+)
+
+// Read the content of the file, on platforms without memory mapping.
+func mapFile(f *os.File, size int) ([]byte, func() error, error) {
+	content := make([]byte, size)
+	if _, err := io.ReadFull(f, content); err != nil {
+		return nil, nil, err
+	}
+	return content, func() error { return nil }, nil
+}
@@ -0,0 +1,213 @@
+package rubik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testPatterns = []Pattern{
+	{Corners: []Corner{URF, DBL}},
+	{Edges: []Edge{UF, FR, DB}},
+	{Corners: []Corner{ULB}, Edges: []Edge{BL, UR}},
+}
+
+var allEdgesPattern = Pattern{Edges: []Edge{UR, UF, UL, UB, DR, DF, DL, DB, FR, FL, BL, BR}}
+
+func TestPatternSize(t *testing.T) {
+	testCases := []struct {
+		Pattern Pattern
+		Size    int
+	}{
+		{CornersPattern, 88179840},
+		{FirstEdgesPattern, 42577920},
+		{LastEdgesPattern, 42577920},
+		{allEdgesPattern, 980995276800},
+		{testPatterns[0], 8 * 7 * 9},
+		{testPatterns[2], 8 * 3 * 12 * 11 * 4},
+		{Pattern{Edges: make([]Edge, 13)}, 0},
+		{Pattern{Corners: CornersPattern.Corners, Edges: allEdgesPattern.Edges}, 0},
+	}
+	for _, tc := range testCases {
+		if got := tc.Pattern.Size(); got != tc.Size {
+			t.Errorf("Wrong size for %v:\nGot  %d\nWant %d", tc.Pattern, got, tc.Size)
+		}
+	}
+}
+
+func TestPatternValidate(t *testing.T) {
+	testCases := []struct {
+		Pattern Pattern
+		Valid   bool
+	}{
+		{CornersPattern, true},
+		{FirstEdgesPattern, true},
+		{Pattern{}, false},
+		{Pattern{Corners: []Corner{URF, URF}}, false},
+		{Pattern{Edges: []Edge{UR, 12}}, false},
+		{Pattern{Corners: []Corner{URF, UFL, ULB, UBR, DFR, DLF, DBL, DRB, URF}}, false},
+		{Pattern{Edges: make([]Edge, 13)}, false},
+		{allEdgesPattern, false},
+		{Pattern{Corners: CornersPattern.Corners, Edges: allEdgesPattern.Edges}, false},
+	}
+	for _, tc := range testCases {
+		if err := tc.Pattern.Validate(); (err == nil) != tc.Valid || (err != nil && !errors.Is(err, ErrInvalidPattern)) {
+			t.Errorf("Wrong validation for %v:\nGot  %v\nWant valid %v", tc.Pattern, err, tc.Valid)
+		}
+	}
+	if _, err := NewPatternDatabase(Pattern{}); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("Empty pattern should be rejected\nGot  %v\nWant %s", err, ErrInvalidPattern)
+	}
+}
+
+func TestPatternIndex(t *testing.T) {
+	for _, pattern := range append(testPatterns, CornersPattern, FirstEdgesPattern) {
+		for index := 0; index < pattern.Size(); index += 1 + pattern.Size()/5000 {
+			c := pattern.cube(index)
+			if got := pattern.index(&c); got != index {
+				t.Fatalf("Wrong index for the cube of index %d of %v:\nGot  %d", index, pattern, got)
+			}
+		}
+	}
+	solved := NewSolvedCubieCube()
+	if got := CornersPattern.index(&solved); got != 0 {
+		t.Errorf("Wrong index for a solved cube:\nGot  %d\nWant 0", got)
+	}
+}
+
+func TestNewPatternDatabase(t *testing.T) {
+	for _, pattern := range testPatterns {
+		db, err := NewPatternDatabase(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Each arrangement is one move away from a closer one, and none is more than
+		// one move away from a neighbor: entries are exact distances.
+		for index := 0; index < pattern.Size(); index++ {
+			distance := db.at(index)
+			if distance == unknownDistance {
+				t.Fatalf("Arrangement %d of %v should be reached", index, pattern)
+			}
+			c := pattern.cube(index)
+			closer := distance == 0
+			for _, move := range Moves {
+				next := c.Multiply(cubieMoves[move])
+				d := db.Distance(next)
+				if d < distance-1 || d > distance+1 {
+					t.Fatalf("Arrangements %d and %d of %v are one move apart: %d and %d", index, pattern.index(&next), pattern, distance, d)
+				}
+				closer = closer || d == distance-1
+			}
+			if !closer {
+				t.Fatalf("Arrangement %d of %v at distance %d has no closer neighbor", index, pattern, distance)
+			}
+		}
+	}
+}
+
+func TestPatternDatabaseHeuristic(t *testing.T) {
+	db, _ := NewPatternDatabase(testPatterns[2])
+	scramble := MustParseAlgorithm("R U2 F' L D")
+	cube := scramble.Apply(NewSolvedCube())
+	c, _ := cube.ToCubie()
+	if estimate := db.Estimate(c); estimate > len(scramble) {
+		t.Errorf("Estimate should not exceed the length of the scramble:\nGot  %d", estimate)
+	}
+	solved, err := SolveIDA(cube, MaxHeuristic(db, DefaultHeuristic))
+	if err != nil || len(solved) != len(scramble) || !solved.Apply(cube).IsSolved() {
+		t.Errorf("Wrong solution for %s\nGot  %s %v", scramble, solved, err)
+	}
+}
+
+func TestPatternDatabaseFile(t *testing.T) {
+	db, _ := NewPatternDatabase(testPatterns[2])
+	path := filepath.Join(t.TempDir(), "test.pdb")
+	if err := db.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadPatternDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loaded.Close()
+	if loaded.Pattern.Size() != db.Pattern.Size() || string(loaded.data) != string(db.data) {
+		t.Errorf("Loaded database should be the saved one:\nGot  %v\nWant %v", loaded.Pattern, db.Pattern)
+	}
+	c, _ := MustParseAlgorithm("F R'").Apply(NewSolvedCube()).ToCubie()
+	if got, want := loaded.Distance(c), db.Distance(c); got != want {
+		t.Errorf("Wrong distance from the loaded database:\nGot  %d\nWant %d", got, want)
+	}
+}
+
+func TestLoadPatternDatabases(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{}
+	built := []*PatternDatabase{}
+	for i, pattern := range testPatterns {
+		db, _ := NewPatternDatabase(pattern)
+		path := filepath.Join(dir, fmt.Sprintf("test%d.pdb", i))
+		if err := db.Save(path); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+		built = append(built, db)
+	}
+	dbs, err := LoadPatternDatabases(paths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dbs.Close()
+	for _, scramble := range []string{"", "F R'", "U L2 B D'"} {
+		cube := MustParseAlgorithm(scramble).Apply(NewSolvedCube())
+		c, _ := cube.ToCubie()
+		if got, want := dbs.Estimate(c), MaxHeuristic(built[0], built[1], built[2]).Estimate(c); got != want {
+			t.Errorf("Wrong estimate for %q:\nGot  %d\nWant %d", scramble, got, want)
+		}
+		solved, err := SolveContext(context.Background(), cube, Options{Heuristic: dbs})
+		if want, _ := Solve(cube); err != nil || len(solved) != len(want) {
+			t.Errorf("Wrong solution for %q with the databases:\nGot  %s %v\nWant %s", scramble, solved, err, want)
+		}
+	}
+
+	if _, err := LoadPatternDatabases(paths[0], filepath.Join(dir, "missing.pdb")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Missing database should not be loaded:\nGot  %v\nWant %s", err, os.ErrNotExist)
+	}
+	if _, err := LoadKorfPatternDatabases(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Korf's databases should be missing:\nGot  %v\nWant %s", err, os.ErrNotExist)
+	}
+}
+
+func TestLoadPatternDatabaseErrors(t *testing.T) {
+	db, _ := NewPatternDatabase(testPatterns[0])
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.pdb")
+	db.Save(path)
+	content, _ := os.ReadFile(path)
+
+	testCases := []struct {
+		Name   string
+		Change func(content []byte) []byte
+		Err    error
+	}{
+		{"magic", func(content []byte) []byte { content[0] = 'X'; return content }, ErrInvalidPatternDatabase},
+		{"version", func(content []byte) []byte { content[4] = 2; return content }, ErrPatternDatabaseVersion},
+		{"pattern", func(content []byte) []byte { content[10] = 6; return content }, ErrInvalidPatternDatabase},
+		{"truncated", func(content []byte) []byte { return content[:len(content)-1] }, ErrInvalidPatternDatabase},
+		{"header", func(content []byte) []byte { return content[:10] }, ErrInvalidPatternDatabase},
+		{"data", func(content []byte) []byte { content[len(content)-1] ^= 1; return content }, ErrPatternDatabaseChecksum},
+	}
+	for _, tc := range testCases {
+		changed := tc.Change(append([]byte{}, content...))
+		path := filepath.Join(dir, tc.Name+".pdb")
+		os.WriteFile(path, changed, 0644)
+		if _, err := LoadPatternDatabase(path); !errors.Is(err, tc.Err) {
+			t.Errorf("Wrong error for a database with a bad %s:\nGot  %v\nWant %s", tc.Name, err, tc.Err)
+		}
+	}
+	if _, err := LoadPatternDatabase(filepath.Join(dir, "missing.pdb")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Wrong error for a missing file:\nGot  %v\nWant %s", err, os.ErrNotExist)
+	}
+}
@@ -0,0 +1,294 @@
+package rubik
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Permutations of the facelets and of the cubies.
+//
+// Every move is a permutation of the 54 facelets, and so are algorithms and cube states
+// reached from a solved cube. Permutations can be composed, inverted and decomposed into
+// cycles, which tells how many times an algorithm must be repeated to return to solved.
+
+// Permutation of the facelets: facelet `i` of the new Cube is facelet `p[i]` of the
+// original one.
+type Permutation [9 * 6]uint8
+
+// Return the permutation leaving every facelet in place.
+func identity() Permutation {
+	var p Permutation
+	for i := range p {
+		p[i] = uint8(i)
+	}
+	return p
+}
+
+// Return the permutation applying `p`, then `q`.
+func (p Permutation) Then(q Permutation) Permutation {
+	var composed Permutation
+	for i, from := range q {
+		composed[i] = p[from]
+	}
+	return composed
+}
+
+// Return the permutation undoing `p`.
+func (p Permutation) Inverse() Permutation {
+	var inverse Permutation
+	for i, from := range p {
+		inverse[from] = uint8(i)
+	}
+	return inverse
+}
+
+// Return `true` if every facelet is left in place.
+func (p Permutation) IsIdentity() bool {
+	return p == identity()
+}
+
+// Decompose the permutation into cycles of facelets, leaving out facelets left in place.
+// In each cycle, the facelet at one index moves to the next index, and the last one moves
+// to the first.
+func (p Permutation) Cycles() [][]int {
+	inverse := p.Inverse()
+	visited := [9 * 6]bool{}
+	cycles := [][]int{}
+	for start := range p {
+		if visited[start] || int(p[start]) == start {
+			continue
+		}
+		cycle := []int{}
+		for i := start; !visited[i]; i = int(inverse[i]) {
+			visited[i] = true
+			cycle = append(cycle, i)
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
+
+// Number of times the permutation must be applied to get back to the identity.
+func (p Permutation) Order() int {
+	order := 1
+	for _, cycle := range p.Cycles() {
+		order = lcm(order, len(cycle))
+	}
+	return order
+}
+
+// Permutation of the facelets performed by this move.
+func (move Move) Permutation() (Permutation, error) {
+	p, found := movePermutations[move]
+	if !found {
+		return Permutation{}, &ParseError{string(move), ErrUnknownMove}
+	}
+	return *p, nil
+}
+
+// Permutation of the facelets performed by this algorithm.
+func (alg Algorithm) Permutation() (Permutation, error) {
+	p := identity()
+	for _, move := range alg {
+		m, err := move.Permutation()
+		if err != nil {
+			return Permutation{}, err
+		}
+		p = p.Then(m)
+	}
+	return p, nil
+}
+
+// Number of times the algorithm must be repeated to get back to the original cube,
+// for instance 6 for "R U R' U'".
+func (alg Algorithm) Order() (int, error) {
+	p, err := alg.Permutation()
+	if err != nil {
+		return 0, err
+	}
+	return p.Order(), nil
+}
+
+// Permutation of the facelets leading from a solved cube to this cube.
+// Centers are left in place.
+func (c CubieCube) Permutation() Permutation {
+	p := identity()
+	for i, facelets := range cornerFacelets {
+		home := cornerFacelets[c.Cp[i]]
+		for n := 0; n < 3; n++ {
+			p[facelets[(n+c.Co[i])%3]] = uint8(home[n])
+		}
+	}
+	for i, facelets := range edgeFacelets {
+		home := edgeFacelets[c.Ep[i]]
+		for n := 0; n < 2; n++ {
+			p[facelets[(n+c.Eo[i])%2]] = uint8(home[n])
+		}
+	}
+	return p
+}
+
+// Permutation of the facelets leading from a solved cube to this cube.
+//
+// Return an error wrapping ErrInvalidCube if the cube cannot be reached from a solved cube.
+func (cube Cube) Permutation() (Permutation, error) {
+	c, err := cube.ToCubie()
+	if err != nil {
+		return Permutation{}, err
+	}
+	return c.Permutation(), nil
+}
+
+// Return the cube undoing this one: multiplying them gives a solved cube.
+func (c CubieCube) Inverse() CubieCube {
+	inverse := c
+	for i, corner := range c.Cp {
+		inverse.Cp[corner] = Corner(i)
+	}
+	for i := range inverse.Co {
+		inverse.Co[i] = (3 - c.Co[inverse.Cp[i]]) % 3
+	}
+	for i, edge := range c.Ep {
+		inverse.Ep[edge] = Edge(i)
+	}
+	for i := range inverse.Eo {
+		inverse.Eo[i] = c.Eo[inverse.Ep[i]]
+	}
+	return inverse
+}
+
+// Cycle of corners: the corner at each position moves to the next one,
+// and the last one moves to the first.
+type CornerCycle struct {
+	Corners []Corner
+	Twist   int // Twist accumulated over one pass of the cycle: 0, 1 or 2
+}
+
+// Cycle of edges: the edge at each position moves to the next one,
+// and the last one moves to the first.
+type EdgeCycle struct {
+	Edges []Edge
+	Flip  int // Flip accumulated over one pass of the cycle: 0 or 1
+}
+
+// String representation, such as "(URF UBR DRB)+" where "+" is a clockwise twist
+// and "-" a counter clockwise twist.
+func (cycle CornerCycle) String() string {
+	s := make([]string, len(cycle.Corners))
+	for i, corner := range cycle.Corners {
+		s[i] = corner.String()
+	}
+	return "(" + strings.Join(s, " ") + ")" + []string{"", "+", "-"}[cycle.Twist]
+}
+
+// String representation, such as "(UF UR UB)" or "(UF)+" for a flipped edge.
+func (cycle EdgeCycle) String() string {
+	s := make([]string, len(cycle.Edges))
+	for i, edge := range cycle.Edges {
+		s[i] = edge.String()
+	}
+	return "(" + strings.Join(s, " ") + ")" + []string{"", "+"}[cycle.Flip]
+}
+
+// Order of the cycle: number of passes bringing every corner back in place, untwisted.
+func (cycle CornerCycle) Order() int {
+	if cycle.Twist != 0 {
+		return 3 * len(cycle.Corners)
+	}
+	return len(cycle.Corners)
+}
+
+// Order of the cycle: number of passes bringing every edge back in place, unflipped.
+func (cycle EdgeCycle) Order() int {
+	if cycle.Flip != 0 {
+		return 2 * len(cycle.Edges)
+	}
+	return len(cycle.Edges)
+}
+
+// Decompose the corners of this cube into cycles, leaving out solved corners.
+// A corner twisted in place is a cycle of length 1.
+func (c CubieCube) CornerCycles() []CornerCycle {
+	position := [8]Corner{}
+	for i, corner := range c.Cp {
+		position[corner] = Corner(i)
+	}
+	visited := [8]bool{}
+	cycles := []CornerCycle{}
+	for start := URF; start <= DRB; start++ {
+		if visited[start] || (c.Cp[start] == start && c.Co[start] == 0) {
+			continue
+		}
+		cycle := CornerCycle{}
+		for i := start; !visited[i]; i = position[i] {
+			visited[i] = true
+			cycle.Corners = append(cycle.Corners, i)
+			cycle.Twist = (cycle.Twist + c.Co[position[i]]) % 3
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
+
+// Decompose the edges of this cube into cycles, leaving out solved edges.
+// An edge flipped in place is a cycle of length 1.
+func (c CubieCube) EdgeCycles() []EdgeCycle {
+	position := [12]Edge{}
+	for i, edge := range c.Ep {
+		position[edge] = Edge(i)
+	}
+	visited := [12]bool{}
+	cycles := []EdgeCycle{}
+	for start := UR; start <= BR; start++ {
+		if visited[start] || (c.Ep[start] == start && c.Eo[start] == 0) {
+			continue
+		}
+		cycle := EdgeCycle{}
+		for i := start; !visited[i]; i = position[i] {
+			visited[i] = true
+			cycle.Edges = append(cycle.Edges, i)
+			cycle.Flip = (cycle.Flip + c.Eo[position[i]]) % 2
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
+
+// Number of times the moves leading to this cube must be repeated to get back to solved.
+func (c CubieCube) Order() int {
+	order := 1
+	for _, cycle := range c.CornerCycles() {
+		order = lcm(order, cycle.Order())
+	}
+	for _, cycle := range c.EdgeCycles() {
+		order = lcm(order, cycle.Order())
+	}
+	return order
+}
+
+// String representation of the cycles, such as "(URF UBR)+ (UF UR UB)".
+func (c CubieCube) String() string {
+	s := []string{}
+	for _, cycle := range c.CornerCycles() {
+		s = append(s, cycle.String())
+	}
+	for _, cycle := range c.EdgeCycles() {
+		s = append(s, cycle.String())
+	}
+	if len(s) == 0 {
+		return "()"
+	}
+	return strings.Join(s, " ")
+}
+
+// Least common multiple.
+func lcm(a, b int) int {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+	if x == 0 {
+		panic(fmt.Sprintf("lcm(%d, %d)", a, b))
+	}
+	return a / x * b
+}
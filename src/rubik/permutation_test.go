@@ -0,0 +1,179 @@
+package rubik
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPermutationInverse(t *testing.T) {
+	for _, move := range AllMoves {
+		p, err := move.Permutation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !p.Then(p.Inverse()).IsIdentity() || !p.Inverse().Then(p).IsIdentity() {
+			t.Errorf("%s followed by its inverse should be the identity", move)
+		}
+		inverse, _ := move.Inverse().Permutation()
+		if inverse != p.Inverse() {
+			t.Errorf("Wrong inverse permutation for %s", move)
+		}
+	}
+}
+
+func TestPermutationThen(t *testing.T) {
+	alg := MustParseAlgorithm("R U F' D2 M x")
+	p, err := alg.Permutation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := NewSolvedCube().permute(&p)
+	want := alg.Apply(NewSolvedCube())
+	if got != want {
+		t.Errorf("Wrong composed permutation for %s:\nGot  %s\nWant %s", alg, got, want)
+	}
+}
+
+func TestPermutationCycles(t *testing.T) {
+	p, _ := Move(UP).Permutation()
+	cycles := p.Cycles()
+	if len(cycles) != 5 {
+		t.Fatalf("U should have 5 cycles of facelets\nGot  %v", cycles)
+	}
+	// The UFL corner moves to ULB
+	if got, want := fmt.Sprint(cycles[0]), "[0 2 8 6]"; got != want {
+		t.Errorf("Wrong first cycle for U:\nGot  %s\nWant %s", got, want)
+	}
+	for _, cycle := range cycles {
+		if len(cycle) != 4 {
+			t.Errorf("U cycles should have length 4\nGot  %v", cycle)
+		}
+	}
+	if len(identity().Cycles()) != 0 {
+		t.Errorf("Identity should have no cycles")
+	}
+}
+
+func TestAlgorithmOrder(t *testing.T) {
+	testCases := []struct {
+		Alg   string
+		Order int
+	}{
+		{"", 1},
+		{"R", 4},
+		{"U2", 2},
+		{"R U R' U'", 6},
+		{"R U", 105},
+		{"R U2 D' B D'", 1260},
+		{"x", 4},
+		{"M2 E2 S2", 2},
+	}
+	for _, tc := range testCases {
+		alg := MustParseAlgorithm(tc.Alg)
+		order, err := alg.Order()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if order != tc.Order {
+			t.Errorf("Wrong order for %q:\nGot  %d\nWant %d", tc.Alg, order, tc.Order)
+		}
+		cube := NewSolvedCube()
+		for i := 0; i < order; i++ {
+			cube = alg.Apply(cube)
+		}
+		if cube != NewSolvedCube() {
+			t.Errorf("%q repeated %d times should solve the cube", tc.Alg, order)
+		}
+	}
+	if _, err := (Algorithm{"Q"}).Order(); err == nil {
+		t.Errorf("Unknown move should return an error")
+	}
+}
+
+func TestCubiePermutation(t *testing.T) {
+	for _, move := range Moves {
+		want, _ := move.Permutation()
+		got := cubieMoves[move].Permutation()
+		if got != want {
+			t.Errorf("Wrong cubie permutation for %s:\nGot  %v\nWant %v", move, got.Cycles(), want.Cycles())
+		}
+	}
+	cube := MustParseAlgorithm("R U2 D' B D' F L'").Apply(NewSolvedCube())
+	p, err := cube.Permutation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := NewSolvedCube().permute(&p); got != cube {
+		t.Errorf("Wrong cube permutation:\nGot  %s\nWant %s", got, cube)
+	}
+}
+
+func TestCubieInverse(t *testing.T) {
+	c := NewSolvedCubieCube()
+	for _, move := range MustParseAlgorithm("R U2 D' B D' F L'") {
+		c = c.MustTurn(move)
+	}
+	if !c.Multiply(c.Inverse()).IsSolved() || !c.Inverse().Multiply(c).IsSolved() {
+		t.Errorf("Cube multiplied by its inverse should be solved: %s", c)
+	}
+	want := NewSolvedCubieCube()
+	for _, move := range MustParseAlgorithm("L F' D B' D U2 R'") {
+		want = want.MustTurn(move)
+	}
+	if c.Inverse() != want {
+		t.Errorf("Wrong inverse:\nGot  %s\nWant %s", c.Inverse(), want)
+	}
+}
+
+func TestCubieCycles(t *testing.T) {
+	testCases := []struct {
+		Alg    string
+		Cycles string
+		Order  int
+	}{
+		{"", "()", 1},
+		{"U", "(URF UFL ULB UBR) (UR UF UL UB)", 4},
+		{"R", "(URF UBR DRB DFR) (UR BR DR FR)", 4},
+		{"U2", "(URF ULB) (UFL UBR) (UR UL) (UF UB)", 2},
+		{"F", "(URF DFR DLF UFL) (UF FR DF FL)", 4},
+		{"R U R' U'", "(URF DFR)+ (ULB UBR)- (UR UB FR)", 6},
+	}
+	for _, tc := range testCases {
+		c := NewSolvedCubieCube()
+		for _, move := range MustParseAlgorithm(tc.Alg) {
+			c = c.MustTurn(move)
+		}
+		if got := c.String(); got != tc.Cycles {
+			t.Errorf("Wrong cycles for %q:\nGot  %s\nWant %s", tc.Alg, got, tc.Cycles)
+		}
+		if got := c.Order(); got != tc.Order {
+			t.Errorf("Wrong order for %q:\nGot  %d\nWant %d", tc.Alg, got, tc.Order)
+		}
+	}
+}
+
+func TestCubieOrderMatchesFacelets(t *testing.T) {
+	for _, s := range []string{"R U R' U'", "R U", "R U2 D' B D'", "F R' D L2 B"} {
+		alg := MustParseAlgorithm(s)
+		c := NewSolvedCubieCube()
+		for _, move := range alg {
+			c = c.MustTurn(move)
+		}
+		want, _ := alg.Order()
+		if got := c.Order(); got != want {
+			t.Errorf("Wrong cubie order for %q:\nGot  %d\nWant %d", s, got, want)
+		}
+	}
+}
+
+func TestCubieCyclesInPlace(t *testing.T) {
+	c := NewSolvedCubieCube()
+	c.Co[URF], c.Co[UFL] = 1, 2
+	c.Eo[UF], c.Eo[UR] = 1, 1
+	if got, want := c.String(), "(URF)+ (UFL)- (UR)+ (UF)+"; got != want {
+		t.Errorf("Wrong cycles for pieces twisted in place:\nGot  %s\nWant %s", got, want)
+	}
+	if got := c.Order(); got != 6 {
+		t.Errorf("Wrong order for pieces twisted in place:\nGot  %d\nWant 6", got)
+	}
+}
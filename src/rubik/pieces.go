@@ -0,0 +1,87 @@
+package rubik
+
+// Pieces (cubies) of the Rubik's Cube.
+//
+// Corners and edges are named after the faces they belong to, and numbered following
+// Kociemba's conventions. Their facelets are listed starting with the U or D facelet
+// (or the F or B facelet for edges of the middle layer), then going clockwise.
+
+// Corner cubies, also used to designate corner positions.
+type Corner int
+
+const (
+	URF Corner = iota
+	UFL
+	ULB
+	UBR
+	DFR
+	DLF
+	DBL
+	DRB
+)
+
+// Edge cubies, also used to designate edge positions.
+type Edge int
+
+const (
+	UR Edge = iota
+	UF
+	UL
+	UB
+	DR
+	DF
+	DL
+	DB
+	FR
+	FL
+	BL
+	BR
+)
+
+// Facelet indices of each corner position.
+var cornerFacelets = [8][3]int{
+	{8, 18, 11},  // URF
+	{6, 9, 38},   // UFL
+	{0, 36, 29},  // ULB
+	{2, 27, 20},  // UBR
+	{47, 17, 24}, // DFR
+	{45, 44, 15}, // DLF
+	{51, 35, 42}, // DBL
+	{53, 26, 33}, // DRB
+}
+
+// Facelet indices of each edge position.
+var edgeFacelets = [12][2]int{
+	{5, 19},  // UR
+	{7, 10},  // UF
+	{3, 37},  // UL
+	{1, 28},  // UB
+	{50, 25}, // DR
+	{46, 16}, // DF
+	{48, 43}, // DL
+	{52, 34}, // DB
+	{14, 21}, // FR
+	{12, 41}, // FL
+	{32, 39}, // BL
+	{30, 23}, // BR
+}
+
+// Index of the center facelet of each face.
+var centerFacelets = [6]int{4, 13, 22, 31, 40, 49}
+
+// Face a facelet belongs to.
+func faceOf(facelet int) int {
+	return facelet / 9
+}
+
+// Faces of each corner cubie, in the same order as its facelets.
+func cornerFaces(corner Corner) [3]int {
+	f := cornerFacelets[corner]
+	return [3]int{faceOf(f[0]), faceOf(f[1]), faceOf(f[2])}
+}
+
+// Faces of each edge cubie, in the same order as its facelets.
+func edgeFaces(edge Edge) [2]int {
+	f := edgeFacelets[edge]
+	return [2]int{faceOf(f[0]), faceOf(f[1])}
+}
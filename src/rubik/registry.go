@@ -0,0 +1,278 @@
+package rubik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Solvers, registered by name.
+//
+// Each solving engine is a Solver, so that tools can pick one by name, or run the same cube
+// through several of them to compare their solutions. The engines of this package are
+// registered as "bfs", "bidirectional", "ida", "kociemba" and "thistlethwaite"; others can be
+// added with RegisterSolver.
+
+// Engine solving cubes.
+type Solver interface {
+	// Name the solver is registered with.
+	Name() string
+
+	// What the solver can do.
+	Capabilities() Capabilities
+
+	// Solve the given cube, and return a list of moves.
+	//
+	// Return an ErrInvalidCube (as a *ValidationError) if the cube cannot be solved,
+	// ErrUnsupportedMetric, ErrUnsupportedGoal or ErrUnsupportedMoves if the solver does not
+	// support the metric, the goal or the moves of the options, ErrUnreachable if the moves
+	// cannot solve the cube, or an ErrNoSolution (usually as a *SearchError) if no solution
+	// was found. Whether the moves can reach the goal is only checked for solved cubes and
+	// targets whose facelets are all known, as with TargetGoal: with other goals, a cube the
+	// moves cannot bring to the goal is searched until a limit of the options is reached.
+	Solve(ctx context.Context, cube Cube, opts Options) (Algorithm, error)
+}
+
+// What a solver can do.
+type Capabilities struct {
+	Optimal  bool     // Solutions are optimal in the metric of the options
+	Parallel bool     // Searches use the workers of the options
+	Goals    bool     // Searches reach the goal of the options, and not only solved cubes
+	Moves    bool     // Solutions only turn the layers of the moves of the options
+	Metrics  []Metric // Metrics supported in the options
+}
+
+// Return `true` if the metric is supported.
+func (c Capabilities) Supports(metric Metric) bool {
+	for _, m := range c.Metrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+var solvers = map[string]Solver{}
+var solversLock sync.RWMutex
+
+// Register a solver under its name.
+//
+// Return ErrDuplicateSolver if a solver is already registered with this name.
+func RegisterSolver(solver Solver) error {
+	solversLock.Lock()
+	defer solversLock.Unlock()
+	if _, found := solvers[solver.Name()]; found {
+		return fmt.Errorf("%w: %q", ErrDuplicateSolver, solver.Name())
+	}
+	solvers[solver.Name()] = solver
+	return nil
+}
+
+// Like RegisterSolver, but panic if the name is already taken.
+func MustRegisterSolver(solver Solver) {
+	if err := RegisterSolver(solver); err != nil {
+		panic(err)
+	}
+}
+
+// Return the solver registered with the given name.
+//
+// Return ErrUnknownSolver if no solver is registered with this name.
+func LookupSolver(name string) (Solver, error) {
+	solversLock.RLock()
+	defer solversLock.RUnlock()
+	solver, found := solvers[name]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSolver, name)
+	}
+	return solver, nil
+}
+
+// Names of the registered solvers, in alphabetical order.
+func SolverNames() []string {
+	solversLock.RLock()
+	defer solversLock.RUnlock()
+	names := make([]string, 0, len(solvers))
+	for name := range solvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Outcome of a solver on a cube.
+type SolverResult struct {
+	Solver   string
+	Solution Algorithm
+	Err      error
+	Duration time.Duration
+}
+
+// Solve the cube with each of the named solvers, one after the other, or with all the
+// registered solvers supporting the metric and the moves of the options if no name is given.
+//
+// Return ErrUnknownSolver if a name is unknown. Errors of the solvers are part of the results.
+func CompareSolvers(ctx context.Context, cube Cube, opts Options, names ...string) ([]SolverResult, error) {
+	if len(names) == 0 {
+		for _, name := range SolverNames() {
+			solver, _ := LookupSolver(name)
+			if c := solver.Capabilities(); c.Supports(opts.Metric) && (opts.Moves == nil || c.Moves) {
+				names = append(names, name)
+			}
+		}
+	}
+	selected := make([]Solver, len(names))
+	for i, name := range names {
+		solver, err := LookupSolver(name)
+		if err != nil {
+			return nil, err
+		}
+		selected[i] = solver
+	}
+	results := make([]SolverResult, len(selected))
+	for i, solver := range selected {
+		start := time.Now()
+		solution, err := solver.Solve(ctx, cube, opts)
+		results[i] = SolverResult{solver.Name(), solution, err, time.Since(start)}
+	}
+	return results, nil
+}
+
+// Solver of this package, running a search within the limits of the options.
+type searchSolver struct {
+	name         string
+	capabilities Capabilities
+	prepare      func(opts Options) // Build the tables, before the deadline is computed
+	search       func(cube Cube, c CubieCube, opts Options, budget *searchBudget) (Algorithm, error)
+}
+
+func (s *searchSolver) Name() string {
+	return s.name
+}
+
+func (s *searchSolver) Capabilities() Capabilities {
+	return s.capabilities
+}
+
+func (s *searchSolver) Solve(ctx context.Context, cube Cube, opts Options) (Algorithm, error) {
+	c, err := cube.ToCubie()
+	if err != nil {
+		return nil, err
+	}
+	if !s.capabilities.Supports(opts.Metric) {
+		return nil, fmt.Errorf("%w: %s by %s", ErrUnsupportedMetric, opts.Metric, s.name)
+	}
+	if opts.Goal != nil && !s.capabilities.Goals {
+		return nil, fmt.Errorf("%w by %s", ErrUnsupportedGoal, s.name)
+	}
+	if opts.Moves != nil && !s.capabilities.Moves {
+		return nil, fmt.Errorf("%w by %s", ErrUnsupportedMoves, s.name)
+	}
+	allowed := opts.Moves
+	if opts.Moves, err = opts.allowedMoves(); err != nil {
+		return nil, err
+	}
+	target, complete := goalTarget(opts.Goal)
+	if allowed != nil && (opts.Goal == nil || complete) {
+		// Searches would never end on cubes the moves cannot bring to the goal
+		g, err := subgroupOf(opts.Moves)
+		if err != nil {
+			return nil, err
+		}
+		var reachable bool
+		if opts.Goal == nil {
+			reachable, _ = g.IsSolvable(cube)
+		} else {
+			reachable, _ = g.canReach(cube, target)
+		}
+		if !reachable {
+			return nil, fmt.Errorf("%w: %s", ErrUnreachable, Algorithm(allowed))
+		}
+	}
+	opts.Heuristic = opts.heuristic()
+	if s.prepare != nil {
+		s.prepare(opts)
+	}
+	opts = opts.withDefaults()
+	if !opts.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, opts.Deadline)
+		defer cancel()
+	}
+	budget := &searchBudget{ctx: ctx, maxNodes: opts.MaxNodes, maxMemory: opts.MaxMemory}
+	return s.search(cube, c, opts, budget)
+}
+
+// Solvers of this package.
+var (
+	bfsSolver = &searchSolver{
+		name:         "bfs",
+		capabilities: Capabilities{Optimal: true, Parallel: true, Goals: true, Moves: true, Metrics: Metrics},
+		search: func(cube Cube, c CubieCube, opts Options, budget *searchBudget) (Algorithm, error) {
+			return solveBFS(cube, opts.Moves, opts.Goal, opts.maxDepth(MAX_IDA_DEPTH), opts.Workers, budget)
+		},
+	}
+	bidirectionalSolver = &searchSolver{
+		name:         "bidirectional",
+		capabilities: Capabilities{Optimal: true, Parallel: true, Moves: true, Metrics: Metrics},
+		search: func(cube Cube, c CubieCube, opts Options, budget *searchBudget) (Algorithm, error) {
+			solution, err := solveBidirectional(cube, opts.Moves, opts.maxDepth(MAX_IDA_DEPTH), opts.Workers, budget)
+			return solution.Algorithm(), err
+		},
+	}
+	idaSolver = &searchSolver{
+		name:         "ida",
+		capabilities: Capabilities{Optimal: true, Parallel: true, Goals: true, Moves: true, Metrics: []Metric{HTM, QTM}},
+		prepare: func(opts Options) {
+			opts.Heuristic.Estimate(NewSolvedCubieCube())
+		},
+		search: func(cube Cube, c CubieCube, opts Options, budget *searchBudget) (Algorithm, error) {
+			return solveIDA(c, opts.Moves, opts.Goal, opts.Heuristic, opts.maxDepth(MAX_IDA_DEPTH), opts.Workers, budget)
+		},
+	}
+	kociembaSolver = &searchSolver{
+		name:         "kociemba",
+		capabilities: Capabilities{Metrics: []Metric{HTM}},
+		prepare: func(opts Options) {
+			newKociembaSearch(NewSolvedCubieCube())
+		},
+		search: func(cube Cube, c CubieCube, opts Options, budget *searchBudget) (Algorithm, error) {
+			s := newKociembaSearch(c)
+			s.budget = budget
+			s.target = KOCIEMBA_TARGET_LENGTH
+			s.maxLength = opts.maxDepth(MAX_KOCIEMBA_LENGTH) + 1
+			s.deadline = opts.Deadline
+			if s.deadline.IsZero() {
+				s.deadline = time.Now().Add(KOCIEMBA_TIME_LIMIT)
+			}
+			return s.run()
+		},
+	}
+	thistlethwaiteSolver = &searchSolver{
+		name:         "thistlethwaite",
+		capabilities: Capabilities{Metrics: []Metric{HTM}},
+		prepare: func(opts Options) {
+			solveThistlethwaite(NewSolvedCubieCube())
+		},
+		search: func(cube Cube, c CubieCube, opts Options, budget *searchBudget) (Algorithm, error) {
+			if !budget.visit() {
+				return nil, budget.err
+			}
+			solution := solveThistlethwaite(c).Algorithm()
+			if len(solution) > opts.maxDepth(len(solution)) {
+				return nil, budget.failure()
+			}
+			return solution, nil
+		},
+	}
+)
+
+func init() {
+	MustRegisterSolver(bfsSolver)
+	MustRegisterSolver(bidirectionalSolver)
+	MustRegisterSolver(idaSolver)
+	MustRegisterSolver(kociembaSolver)
+	MustRegisterSolver(thistlethwaiteSolver)
+}
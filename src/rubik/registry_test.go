@@ -0,0 +1,117 @@
+package rubik
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// Solver returning no move, for tests.
+type lazySolver struct{}
+
+func (lazySolver) Name() string               { return "lazy" }
+func (lazySolver) Capabilities() Capabilities { return Capabilities{Metrics: Metrics} }
+func (lazySolver) Solve(ctx context.Context, cube Cube, opts Options) (Algorithm, error) {
+	return Algorithm{}, nil
+}
+
+func TestSolverNames(t *testing.T) {
+	names := SolverNames()
+	for _, name := range []string{"bfs", "bidirectional", "ida", "kociemba", "thistlethwaite"} {
+		solver, err := LookupSolver(name)
+		if err != nil || solver.Name() != name {
+			t.Errorf("Solver %s should be registered\nGot  %v %v\nWant %s", name, solver, err, names)
+		}
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Errorf("Solver names should be sorted:\nGot  %v", names)
+		}
+	}
+}
+
+func TestRegisterSolver(t *testing.T) {
+	if err := RegisterSolver(lazySolver{}); err != nil {
+		t.Fatal(err)
+	}
+	if solver, err := LookupSolver("lazy"); err != nil || solver.Name() != "lazy" {
+		t.Errorf("Registered solver should be found\nGot  %v %v", solver, err)
+	}
+	if err := RegisterSolver(lazySolver{}); !errors.Is(err, ErrDuplicateSolver) {
+		t.Errorf("Solver should not be registered twice\nGot  %v\nWant %s", err, ErrDuplicateSolver)
+	}
+	if _, err := LookupSolver("unknown"); !errors.Is(err, ErrUnknownSolver) {
+		t.Errorf("Unknown solver should not be found\nGot  %v\nWant %s", err, ErrUnknownSolver)
+	}
+}
+
+func TestSolvers(t *testing.T) {
+	cube := MustParseAlgorithm("R U F'").Apply(NewSolvedCube())
+	invalid := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	for _, name := range []string{"bfs", "bidirectional", "ida", "kociemba", "thistlethwaite"} {
+		solver, _ := LookupSolver(name)
+		solved, err := solver.Solve(context.Background(), cube, Options{})
+		if err != nil || !solved.Apply(cube).IsSolved() {
+			t.Errorf("Wrong solution by %s\nGot  %s %v", name, solved, err)
+		}
+		if solver.Capabilities().Optimal && len(solved) != 3 {
+			t.Errorf("Solution by %s should be optimal\nGot  %s\nWant 3 moves", name, solved)
+		}
+		if _, err := solver.Solve(context.Background(), invalid, Options{}); !errors.Is(err, ErrInvalidCube) {
+			t.Errorf("Invalid cube should not be solved by %s\nGot  %v\nWant %s", name, err, ErrInvalidCube)
+		}
+		_, err = solver.Solve(context.Background(), cube, Options{Moves: []Move{RIGHT, UP, FRONT}})
+		if supported := solver.Capabilities().Moves; supported != (err == nil) ||
+			(!supported && !errors.Is(err, ErrUnsupportedMoves)) {
+			t.Errorf("Wrong support of restricted moves by %s\nGot  %v\nWant supported %v", name, err, supported)
+		}
+		for _, metric := range Metrics {
+			_, err := solver.Solve(context.Background(), NewSolvedCube(), Options{Metric: metric})
+			if supported := solver.Capabilities().Supports(metric); supported != (err == nil) ||
+				(!supported && !errors.Is(err, ErrUnsupportedMetric)) {
+				t.Errorf("Wrong support of %s by %s\nGot  %v\nWant supported %v", metric, name, err, supported)
+			}
+		}
+	}
+}
+
+func TestSolversCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, cube := NewScrambler(1).RandomState()
+	for _, name := range []string{"bfs", "bidirectional", "ida", "kociemba", "thistlethwaite"} {
+		solver, _ := LookupSolver(name)
+		if _, err := solver.Solve(ctx, cube, Options{}); !errors.Is(err, ErrCanceled) {
+			t.Errorf("Canceled search by %s should fail\nGot  %v\nWant %s", name, err, ErrCanceled)
+		}
+	}
+}
+
+func TestCompareSolvers(t *testing.T) {
+	cube := MustParseAlgorithm("R2 D B'").Apply(NewSolvedCube())
+	results, err := CompareSolvers(context.Background(), cube, Options{}, "ida", "kociemba")
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Wrong comparison\nGot  %v %v", results, err)
+	}
+	for i, name := range []string{"ida", "kociemba"} {
+		result := results[i]
+		if result.Solver != name || result.Err != nil || !result.Solution.Apply(cube).IsSolved() || result.Duration <= 0 {
+			t.Errorf("Wrong result for %s\nGot  %+v", name, result)
+		}
+	}
+	if _, err := CompareSolvers(context.Background(), cube, Options{}, "ida", "unknown"); !errors.Is(err, ErrUnknownSolver) {
+		t.Errorf("Unknown solver should not be compared\nGot  %v\nWant %s", err, ErrUnknownSolver)
+	}
+	results, _ = CompareSolvers(context.Background(), cube, Options{Moves: []Move{RIGHT, DOWN, BACK}})
+	for _, result := range results {
+		if solver, _ := LookupSolver(result.Solver); !solver.Capabilities().Moves || result.Err != nil {
+			t.Errorf("Solver %s should not be compared with restricted moves\nGot  %v", result.Solver, result.Err)
+		}
+	}
+	results, _ = CompareSolvers(context.Background(), cube, Options{Metric: STM})
+	for _, result := range results {
+		if solver, _ := LookupSolver(result.Solver); !solver.Capabilities().Supports(STM) {
+			t.Errorf("Solver %s should not be compared in STM", result.Solver)
+		}
+	}
+}
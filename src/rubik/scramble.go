@@ -0,0 +1,327 @@
+package rubik
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Scrambles, aka random cubes and the moves leading to them.
+//
+// Random-state scrambles pick a reachable cube uniformly at random, as done in competitions,
+// then compute a sequence of moves leading to it from a solved cube.
+
+// Scrambled cubes must be at least this number of moves away from a solved cube.
+const MIN_SCRAMBLE_DISTANCE = 4
+
+// Generator of random cubes.
+type Scrambler struct {
+	rand *rand.Rand
+}
+
+// Build a new Scrambler. Scramblers built with the same seed generate the same scrambles.
+func NewScrambler(seed int64) *Scrambler {
+	return &Scrambler{rand.New(rand.NewSource(seed))}
+}
+
+// Pick a cube uniformly at random among the cubes that can be reached from a solved cube.
+func (s *Scrambler) RandomCubieCube() CubieCube {
+	c := NewSolvedCubieCube()
+	cornerPerm := s.rand.Perm(len(c.Cp))
+	for i, corner := range cornerPerm {
+		c.Cp[i] = Corner(corner)
+	}
+	edgePerm := s.rand.Perm(len(c.Ep))
+	if parity(cornerPerm) != parity(edgePerm) {
+		edgePerm[10], edgePerm[11] = edgePerm[11], edgePerm[10]
+	}
+	for i, edge := range edgePerm {
+		c.Ep[i] = Edge(edge)
+	}
+
+	twist, flip := 0, 0
+	for i := 0; i < len(c.Co)-1; i++ {
+		c.Co[i] = s.rand.Intn(3)
+		twist += c.Co[i]
+	}
+	c.Co[len(c.Co)-1] = (3 - twist%3) % 3
+	for i := 0; i < len(c.Eo)-1; i++ {
+		c.Eo[i] = s.rand.Intn(2)
+		flip += c.Eo[i]
+	}
+	c.Eo[len(c.Eo)-1] = flip % 2
+	return c
+}
+
+// Pick a random cube at least MIN_SCRAMBLE_DISTANCE moves away from a solved cube.
+// Return the moves leading to this cube from NewSolvedCube(), and the cube itself.
+func (s *Scrambler) RandomState() (Algorithm, Cube) {
+	c := s.RandomCubieCube()
+	for isWithin(c, MIN_SCRAMBLE_DISTANCE-1) {
+		c = s.RandomCubieCube()
+	}
+	return solveByCommutators(c).Inverse().Simplify(), c.ToFacelets()
+}
+
+// Pick `length` random moves among the given ones, or among Moves if none are given.
+// Return these moves and the cube they lead to from NewSolvedCube().
+//
+// As in competition scrambles, two consecutive moves never turn the same layer, and two
+// moves turning opposite layers are never followed by a move turning the first layer again,
+// such as "R L R". Return an error wrapping ErrUnknownMove if a move is unknown, or
+// ErrInvalidMoveSet if the moves cannot be chained, for instance when they all turn
+// the same layer.
+func (s *Scrambler) RandomMoves(length int, moves ...Move) (Algorithm, Cube, error) {
+	if len(moves) == 0 {
+		moves = Moves
+	}
+	for _, move := range moves {
+		if _, found := movePermutations[move]; !found {
+			return nil, Cube{}, &ParseError{string(move), ErrUnknownMove}
+		}
+	}
+
+	alg := Algorithm{}
+	cube := NewSolvedCube()
+	candidates := make([]Move, 0, len(moves))
+	for len(alg) < length {
+		candidates = candidates[:0]
+		for _, move := range moves {
+			if canFollow(alg, move) {
+				candidates = append(candidates, move)
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, Cube{}, ErrInvalidMoveSet
+		}
+		move := candidates[s.rand.Intn(len(candidates))]
+		alg = append(alg, move)
+		cube = cube.MustTurn(move)
+	}
+	return alg, cube, nil
+}
+
+// Return `true` if the move can be appended to the scramble: it must not turn the same
+// layer as the last move, nor as the one before when both turn around the same axis.
+func canFollow(alg Algorithm, move Move) bool {
+	layer, _ := splitMove(move)
+	for i := len(alg) - 1; i >= 0 && i >= len(alg)-2; i-- {
+		previous, _ := splitMove(alg[i])
+		if previous == layer {
+			return false
+		}
+		if axisOf(previous) != axisOf(layer) {
+			break
+		}
+	}
+	return true
+}
+
+// Return `true` if the cube can be solved in at most `depth` face moves.
+func isWithin(c CubieCube, depth int) bool {
+	if c.IsSolved() {
+		return true
+	}
+	if depth == 0 {
+		return false
+	}
+	for _, move := range Moves {
+		if isWithin(c.Multiply(cubieMoves[move]), depth-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sequence of moves with a known effect on the cube.
+type macro struct {
+	Alg    Algorithm
+	Effect CubieCube
+}
+
+// 3-cycle of pieces: the piece at position `b` moves to `a`, the one at `c` moves to `b`,
+// the one at `a` moves to `c`. Their twists (or flips) change by `oa`, `ob`, and whatever
+// is left for the third one.
+type cycleKey struct {
+	a, b, c int
+	oa, ob  int
+}
+
+// Pieces of one kind, corners or edges, solved with 3-cycles involving a buffer position.
+type pieceSet struct {
+	count       int
+	orientation int // Number of orientations of each piece
+	buffer      int
+	bases       []Algorithm
+
+	// Piece found at the given position, and its orientation.
+	piece func(c *CubieCube, position int) (int, int)
+
+	once   sync.Once
+	cycles map[cycleKey]macro
+}
+
+var cornerSet = &pieceSet{
+	count:       8,
+	orientation: 3,
+	buffer:      int(URF),
+	bases:       []Algorithm{MustParseAlgorithm("R U R' D R U' R' D'")},
+	piece: func(c *CubieCube, position int) (int, int) {
+		return int(c.Cp[position]), c.Co[position]
+	},
+}
+
+var edgeSet = &pieceSet{
+	count:       12,
+	orientation: 2,
+	buffer:      int(UF),
+	bases: []Algorithm{
+		MustParseAlgorithm("R2 U R U R' U' R' U' R' U R'"),
+		MustParseAlgorithm("L2 U' L' U' L U L U L U' L"),
+		MustParseAlgorithm("F2 U F U F' U' F' U' F' U F'"),
+	},
+	piece: func(c *CubieCube, position int) (int, int) {
+		return int(c.Ep[position]), c.Eo[position]
+	},
+}
+
+// Effect of an algorithm on a solved cube, using face moves only.
+func cubieEffect(alg Algorithm) CubieCube {
+	c := NewSolvedCubieCube()
+	for _, move := range alg {
+		c = c.Multiply(cubieMoves[move])
+	}
+	return c
+}
+
+// Find a 3-cycle for each pair of positions and orientations involving the buffer,
+// by conjugating the base algorithms with every setup of up to 4 face moves.
+func (set *pieceSet) init() {
+	set.cycles = map[cycleKey]macro{}
+	total := (set.count - 1) * (set.count - 2) * set.orientation * set.orientation
+	bases := []macro{}
+	for _, base := range set.bases {
+		bases = append(bases, macro{base, cubieEffect(base)})
+		bases = append(bases, macro{base.Inverse(), cubieEffect(base.Inverse())})
+	}
+
+	var search func(setup Algorithm, s, inverse CubieCube, depth int)
+	search = func(setup Algorithm, s, inverse CubieCube, depth int) {
+		if len(set.cycles) == total {
+			return
+		}
+		if depth > 0 {
+			for _, move := range Moves {
+				if len(setup) > 0 && move[0] == setup[len(setup)-1][0] {
+					continue
+				}
+				search(append(setup, move), s.Multiply(cubieMoves[move]),
+					cubieMoves[move.Inverse()].Multiply(inverse), depth-1)
+			}
+			return
+		}
+		for _, base := range bases {
+			effect := s.Multiply(base.Effect).Multiply(inverse)
+			a := set.buffer
+			b, oa := set.piece(&effect, a)
+			if b == a {
+				continue
+			}
+			c, ob := set.piece(&effect, b)
+			key := cycleKey{a, b, c, oa, ob}
+			if _, found := set.cycles[key]; !found {
+				alg := append(append(append(Algorithm{}, setup...), base.Alg...), setup.Inverse()...)
+				set.cycles[key] = macro{alg, effect}
+			}
+		}
+	}
+	solved := NewSolvedCubieCube()
+	for depth := 0; depth <= 4; depth++ {
+		search(Algorithm{}, solved, solved, depth)
+	}
+	if len(set.cycles) != total {
+		panic("missing 3-cycles")
+	}
+}
+
+// Solve the pieces of this set, and return the cube and the moves applied so far.
+// The permutation of the pieces must be even.
+func (set *pieceSet) solve(c CubieCube, alg Algorithm) (CubieCube, Algorithm) {
+	set.once.Do(set.init)
+	n := set.orientation
+	buffer := set.buffer
+	isSolved := func(position int) bool {
+		piece, o := set.piece(&c, position)
+		return piece == position && o == 0
+	}
+	unsolved := func(except ...int) int {
+	next:
+		for position := 0; position < set.count; position++ {
+			for _, e := range except {
+				if position == e {
+					continue next
+				}
+			}
+			if !isSolved(position) {
+				return position
+			}
+		}
+		return -1
+	}
+
+	for {
+		var key cycleKey
+		if piece, o := set.piece(&c, buffer); piece != buffer {
+			// Send the piece of the buffer home, and bring the buffer piece back if possible
+			target := piece
+			source := -1
+			for position := 0; position < set.count; position++ {
+				if p, _ := set.piece(&c, position); p == buffer {
+					source = position
+				}
+			}
+			if source == target {
+				source = unsolved(buffer, target)
+			}
+			oc := (n - o) % n
+			oa := 0
+			if p, os := set.piece(&c, source); p == buffer {
+				oa = (n - os) % n
+			}
+			key = cycleKey{buffer, source, target, oa, (2*n - oa - oc) % n}
+		} else {
+			// Break into an unsolved cycle
+			first := unsolved(buffer)
+			if first < 0 {
+				return c, alg
+			}
+			second := unsolved(buffer, first)
+			if second < 0 {
+				second = (first + 1) % set.count
+				if second == buffer {
+					second = (second + 1) % set.count
+				}
+			}
+			key = cycleKey{buffer, second, first, 0, 0}
+		}
+		m := set.cycles[key]
+		c = c.Multiply(m.Effect)
+		alg = append(alg, m.Alg...)
+	}
+}
+
+// Return a sequence of face moves solving this cube, made of 3-cycles of pieces.
+// The sequence is long, but found instantly for any cube.
+func solveByCommutators(c CubieCube) Algorithm {
+	alg := Algorithm{}
+	cornerPerm := make([]int, len(c.Cp))
+	for i, corner := range c.Cp {
+		cornerPerm[i] = int(corner)
+	}
+	if parity(cornerPerm) != 0 {
+		c = c.Multiply(cubieMoves[UP])
+		alg = append(alg, UP)
+	}
+	c, alg = cornerSet.solve(c, alg)
+	_, alg = edgeSet.solve(c, alg)
+	return alg
+}
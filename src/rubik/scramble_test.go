@@ -0,0 +1,165 @@
+package rubik
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRandomCubieCubeIsValid(t *testing.T) {
+	s := NewScrambler(1)
+	for i := 0; i < 100; i++ {
+		c := s.RandomCubieCube()
+		if err := c.ToFacelets().Validate(); err != nil {
+			t.Fatalf("Random cube should be valid: %s\nGot  %s", c.ToFacelets(), err)
+		}
+	}
+}
+
+func TestRandomCubieCubeIsUniform(t *testing.T) {
+	// Each corner should be found at each position with each twist about as often
+	s := NewScrambler(2)
+	counts := [8][3]int{}
+	n := 24000
+	for i := 0; i < n; i++ {
+		c := s.RandomCubieCube()
+		counts[c.Cp[URF]][c.Co[URF]]++
+	}
+	for corner, twists := range counts {
+		for twist, count := range twists {
+			if count < n/24*8/10 || count > n/24*12/10 {
+				t.Errorf("Corner %s with twist %d found %d times in URF out of %d", Corner(corner), twist, count, n)
+			}
+		}
+	}
+}
+
+func TestRandomStateIsReproducible(t *testing.T) {
+	alg1, cube1 := NewScrambler(42).RandomState()
+	alg2, cube2 := NewScrambler(42).RandomState()
+	if cube1 != cube2 || alg1.String() != alg2.String() {
+		t.Errorf("Scramblers with the same seed should agree:\nGot  %s\nWant %s", alg2, alg1)
+	}
+	_, cube3 := NewScrambler(43).RandomState()
+	if cube1 == cube3 {
+		t.Errorf("Scramblers with different seeds should disagree: %s", cube1)
+	}
+}
+
+func TestRandomStateSequence(t *testing.T) {
+	s := NewScrambler(3)
+	for i := 0; i < 20; i++ {
+		alg, cube := s.RandomState()
+		if got := alg.Apply(NewSolvedCube()); got != cube {
+			t.Fatalf("Scramble %s should lead to the cube\nGot  %s\nWant %s", alg, got, cube)
+		}
+		for _, move := range alg {
+			if isRotation(move) || isSliceTurn(move) {
+				t.Errorf("Scramble should only use face moves: %s", alg)
+			}
+		}
+		if c, _ := cube.ToCubie(); isWithin(c, MIN_SCRAMBLE_DISTANCE-1) {
+			t.Errorf("Scrambled cube is too close to solved: %s", alg)
+		}
+	}
+}
+
+func TestSolveByCommutators(t *testing.T) {
+	s := NewScrambler(4)
+	for i := 0; i < 100; i++ {
+		c := s.RandomCubieCube()
+		solution := solveByCommutators(c)
+		for _, move := range solution {
+			c = c.Multiply(cubieMoves[move])
+		}
+		if !c.IsSolved() {
+			t.Fatalf("Solution %s should solve the cube\nGot  %s", solution, c)
+		}
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+	c := cubieEffect(MustParseAlgorithm("R U F"))
+	if !isWithin(c, 3) || isWithin(c, 2) {
+		t.Errorf("R U F should be exactly 3 moves away from solved")
+	}
+}
+
+func TestRandomMoves(t *testing.T) {
+	s := NewScrambler(5)
+	for i := 0; i < 50; i++ {
+		alg, cube, err := s.RandomMoves(25)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(alg) != 25 {
+			t.Errorf("Wrong scramble length: %s", alg)
+		}
+		if got := alg.Apply(NewSolvedCube()); got != cube {
+			t.Errorf("Scramble %s should lead to the cube\nGot  %s\nWant %s", alg, got, cube)
+		}
+		for j, move := range alg {
+			layer, _ := splitMove(move)
+			if j > 0 {
+				if previous, _ := splitMove(alg[j-1]); previous == layer {
+					t.Errorf("Consecutive moves turn the same face: %s", alg)
+				}
+			}
+			if j > 1 {
+				previous, _ := splitMove(alg[j-1])
+				first, _ := splitMove(alg[j-2])
+				if first == layer && axisOf(previous) == axisOf(layer) {
+					t.Errorf("Opposite faces turned around another one: %s", alg)
+				}
+			}
+		}
+	}
+}
+
+func TestRandomMovesIsReproducible(t *testing.T) {
+	alg1, _, _ := NewScrambler(42).RandomMoves(20)
+	alg2, _, _ := NewScrambler(42).RandomMoves(20)
+	if alg1.String() != alg2.String() {
+		t.Errorf("Scramblers with the same seed should agree:\nGot  %s\nWant %s", alg2, alg1)
+	}
+}
+
+func TestRandomMovesSubset(t *testing.T) {
+	alg, _, err := NewScrambler(6).RandomMoves(30, RIGHT, RIGHT_COUNTER, UP, UP_HALF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, move := range alg {
+		if move != RIGHT && move != RIGHT_COUNTER && move != UP && move != UP_HALF {
+			t.Fatalf("Unexpected move %s in %s", move, alg)
+		}
+		if i > 0 && move[0] == alg[i-1][0] {
+			t.Fatalf("Consecutive moves turn the same face: %s", alg)
+		}
+	}
+}
+
+func TestRandomMovesDepth(t *testing.T) {
+	s := NewScrambler(7)
+	for i := 0; i < 20; i++ {
+		_, cube, _ := s.RandomMoves(3)
+		if c, _ := cube.ToCubie(); !isWithin(c, 3) {
+			t.Errorf("Cube should be at most 3 moves away from solved: %s", cube)
+		}
+	}
+}
+
+func TestRandomMovesInvalid(t *testing.T) {
+	s := NewScrambler(8)
+	if _, _, err := s.RandomMoves(5, "Q"); !errors.Is(err, ErrUnknownMove) {
+		t.Errorf("Unknown move should be rejected\nGot  %v\nWant %s", err, ErrUnknownMove)
+	}
+	if _, _, err := s.RandomMoves(2, RIGHT, RIGHT_HALF); !errors.Is(err, ErrInvalidMoveSet) {
+		t.Errorf("Moves of a single face cannot be chained\nGot  %v\nWant %s", err, ErrInvalidMoveSet)
+	}
+	if alg, _, err := s.RandomMoves(1, RIGHT, RIGHT_HALF); err != nil || len(alg) != 1 {
+		t.Errorf("A single move should be picked\nGot  %s %v", alg, err)
+	}
+	if _, _, err := s.RandomMoves(3, RIGHT, LEFT); !errors.Is(err, ErrInvalidMoveSet) {
+		t.Errorf("Opposite faces cannot be chained more than twice\nGot  %v\nWant %s", err, ErrInvalidMoveSet)
+	}
+}
@@ -0,0 +1,256 @@
+package rubik
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Bounded searches.
+//
+// Optimal solvers may explore a huge number of cubes before finding a solution. Searches are
+// given limits on their depth, on the number of cubes they explore and on the memory they
+// use, and can be canceled through a context. They stop with a *SearchError telling which
+// limit was reached.
+
+// Memory used at most by a search, in bytes, unless told otherwise.
+const DEFAULT_MAX_MEMORY = 1 << 30
+
+// Number of explored cubes between two checks of the context.
+const CANCEL_CHECK_INTERVAL = 1024
+
+// Options of a search. The zero value gives an optimal solution in the half turn metric,
+// with no limit but the default memory budget, using a single goroutine.
+//
+// Cubes the Moves cannot bring to the goal fail at once with ErrUnreachable when the goal is
+// solved cubes (nil Goal) or a target whose facelets are all known (TargetGoal). Other goals,
+// such as partially masked targets or predicates, are not checked: such cubes are searched
+// until a limit is reached.
+type Options struct {
+	Metric    Metric        // Metric the solution is optimal in, HTM by default
+	Goal      Goal          // Cubes the search looks for, solved cubes by default
+	Moves     []Move        // Layers the solution turns, given by any of their moves, all of them by default
+	Heuristic Heuristic     // Heuristic used by IDA*, the one of the goal by default
+	MaxDepth  int           // Longest solution, in the metric, MAX_IDA_DEPTH by default for optimal solvers
+	MaxNodes  int           // Number of cubes explored at most, unlimited by default
+	MaxMemory int           // Bytes used at most by the search, DEFAULT_MAX_MEMORY by default
+	Deadline  time.Time     // Time the search stops at, none by default
+	Timeout   time.Duration // Time given to each search, unlimited by default
+	Workers   int           // Goroutines searching in parallel, 1 by default; runtime.NumCPU() uses all cores
+}
+
+// Options with the default values filled in, and the timeout turned into a deadline.
+func (opts Options) withDefaults() Options {
+	opts.Heuristic = opts.heuristic()
+	if opts.MaxMemory <= 0 {
+		opts.MaxMemory = DEFAULT_MAX_MEMORY
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.Timeout > 0 {
+		if deadline := time.Now().Add(opts.Timeout); opts.Deadline.IsZero() || deadline.Before(opts.Deadline) {
+			opts.Deadline = deadline
+		}
+		opts.Timeout = 0
+	}
+	return opts
+}
+
+// Heuristic of the options: DefaultHeuristic for solved cubes, the heuristic of the goal if
+// it has one, or NoHeuristic otherwise, unless one is set.
+func (opts Options) heuristic() Heuristic {
+	if opts.Heuristic != nil {
+		return opts.Heuristic
+	}
+	if opts.Goal == nil {
+		return DefaultHeuristic
+	}
+	if h, ok := opts.Goal.(Heuristic); ok {
+		return h
+	}
+	return NoHeuristic
+}
+
+// Moves of the metric turning the layers of the moves of the options, or all the moves of
+// the metric if none is set.
+//
+// Return ErrUnsupportedMoves if the options allow no move at all, an ErrUnknownMove (as a
+// *ParseError) if a move of the options is not supported, or ErrUnsupportedMetric if no move
+// of the metric turns its layer.
+func (opts Options) allowedMoves() ([]Move, error) {
+	if opts.Moves == nil {
+		return opts.Metric.Moves(), nil
+	}
+	if len(opts.Moves) == 0 {
+		return nil, fmt.Errorf("%w: no move allowed", ErrUnsupportedMoves)
+	}
+	layers := map[string]bool{}
+	for _, move := range opts.Moves {
+		if _, err := move.Permutation(); err != nil {
+			return nil, err
+		}
+		layer, _ := splitMove(move)
+		layers[layer] = false
+	}
+	moves := []Move{}
+	for _, move := range opts.Metric.Moves() {
+		layer, _ := splitMove(move)
+		if _, found := layers[layer]; found {
+			moves = append(moves, move)
+			layers[layer] = true
+		}
+	}
+	for _, move := range opts.Moves {
+		if layer, _ := splitMove(move); !layers[layer] {
+			return nil, fmt.Errorf("%w: %s in %s", ErrUnsupportedMetric, move, opts.Metric)
+		}
+	}
+	return moves, nil
+}
+
+// Longest solution searched for, or the given default if none is set.
+func (opts Options) maxDepth(defaultDepth int) int {
+	if opts.MaxDepth <= 0 {
+		return defaultDepth
+	}
+	return opts.MaxDepth
+}
+
+// Error describing why a search stopped before finding a solution.
+type SearchError struct {
+	Reason error // ErrDepthLimit, ErrNodeLimit, ErrMemoryLimit or ErrCanceled
+	Cause  error // Error of the context when canceled, such as context.DeadlineExceeded
+	Nodes  int   // Number of cubes explored
+}
+
+func (e *SearchError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s after %d cubes: %s", e.Reason, e.Nodes, e.Cause)
+	}
+	return fmt.Sprintf("%s after %d cubes", e.Reason, e.Nodes)
+}
+
+// A *SearchError is an ErrNoSolution, as well as its reason and cause.
+func (e *SearchError) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{e.Reason, ErrNoSolution, e.Cause}
+	}
+	return []error{e.Reason, ErrNoSolution}
+}
+
+// Solve the given cube, and return a solution that is optimal in the metric of the options.
+// Cubes are solved with IDA* in the half turn and quarter turn metrics, and with a BFS
+// in the other metrics. The search stops when the context is canceled.
+//
+// Return an ErrInvalidCube (as a *ValidationError) if the cube cannot be solved,
+// ErrUnsupportedMoves if the options allow no move, ErrUnreachable if the moves of the
+// options cannot solve it, or a *SearchError wrapping ErrDepthLimit, ErrNodeLimit,
+// ErrMemoryLimit or ErrCanceled if no solution was found within the limits of the options.
+func SolveContext(ctx context.Context, cube Cube, opts Options) (Algorithm, error) {
+	return defaultSolver(opts.Metric).Solve(ctx, cube, opts)
+}
+
+// Solver of SolveContext: IDA* in the half turn and quarter turn metrics, BFS otherwise.
+func defaultSolver(metric Metric) Solver {
+	if metric == HTM || metric == QTM {
+		return idaSolver
+	}
+	return bfsSolver
+}
+
+// Limits of a search, and resources used so far.
+type searchBudget struct {
+	ctx       context.Context
+	maxNodes  int // No limit if 0
+	maxMemory int // No limit if 0
+	nodes     int
+	memory    int
+	err       error // Why the search stopped, if it did
+
+	// Budget of the whole search, for a worker of a parallel search. Workers only count
+	// their cubes in it every CANCEL_CHECK_INTERVAL cubes, and when they are done.
+	parent *searchBudget
+	synced int        // Cubes of the worker already counted in the budget of the whole search
+	lock   sync.Mutex // Guards the budget of the whole search while its workers run
+}
+
+// Count an explored cube. Return `false` if the search must stop.
+func (b *searchBudget) visit() bool {
+	if b.err != nil {
+		return false
+	}
+	b.nodes++
+	if b.parent != nil {
+		return b.nodes%CANCEL_CHECK_INTERVAL != 0 || b.sync()
+	}
+	if b.maxNodes > 0 && b.nodes > b.maxNodes {
+		b.stop(ErrNodeLimit, nil)
+		return false
+	}
+	if b.nodes%CANCEL_CHECK_INTERVAL == 1 {
+		if err := b.ctx.Err(); err != nil {
+			b.stop(ErrCanceled, err)
+			return false
+		}
+	}
+	return true
+}
+
+// Count memory allocated (or released, if negative) by the search.
+// Return `false` if the search must stop.
+func (b *searchBudget) allocate(bytes int) bool {
+	b.memory += bytes
+	if b.maxMemory > 0 && b.memory > b.maxMemory {
+		b.stop(ErrMemoryLimit, nil)
+		return false
+	}
+	return true
+}
+
+// Stop the search for the given reason.
+func (b *searchBudget) stop(reason, cause error) {
+	if b.err == nil {
+		b.err = &SearchError{Reason: reason, Cause: cause, Nodes: b.nodes}
+	}
+}
+
+// Error to return when the search ended without solution: ErrDepthLimit unless it was stopped.
+func (b *searchBudget) failure() error {
+	b.stop(ErrDepthLimit, nil)
+	return b.err
+}
+
+// Budget of a worker of a parallel search, sharing the limits of this budget.
+// The worker also stops when the given context is canceled.
+//
+// Only the workers may use the budget of the whole search while they run.
+func (b *searchBudget) fork(ctx context.Context) *searchBudget {
+	return &searchBudget{ctx: ctx, parent: b}
+}
+
+// Count the cubes explored by a worker in the budget of the whole search.
+// Return `false` if the whole search must stop, or if the context of the worker is canceled.
+func (b *searchBudget) sync() bool {
+	p := b.parent
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.nodes += b.nodes - b.synced
+	b.synced = b.nodes
+	if p.maxNodes > 0 && p.nodes > p.maxNodes {
+		p.stop(ErrNodeLimit, nil)
+	}
+	if err := p.ctx.Err(); err != nil {
+		p.stop(ErrCanceled, err)
+	}
+	if p.err != nil {
+		b.err = p.err
+		return false
+	}
+	if err := b.ctx.Err(); err != nil {
+		b.stop(ErrCanceled, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,207 @@
+package rubik
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSolveContext(t *testing.T) {
+	testCases := []struct {
+		Scramble string
+		Metric   Metric
+		Length   int
+	}{
+		{"", HTM, 0},
+		{"R U F", HTM, 3},
+		{"U2 R", QTM, 3},
+		{"M2 U", STM, 2},
+	}
+	for _, tc := range testCases {
+		cube := MustParseAlgorithm(tc.Scramble).Apply(NewSolvedCube())
+		solved, err := SolveContext(context.Background(), cube, Options{Metric: tc.Metric})
+		if err != nil || tc.Metric.Length(solved) != tc.Length || !solved.Apply(cube).IsSolved() {
+			t.Errorf("Wrong solution for %q in %s\nGot  %s %v\nWant %d moves", tc.Scramble, tc.Metric, solved, err, tc.Length)
+		}
+	}
+}
+
+func TestSolveContextErrors(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, scrambled := NewScrambler(1).RandomState()
+	near := MustParseAlgorithm("R U F' L2 D").Apply(NewSolvedCube())
+
+	testCases := []struct {
+		Name    string
+		Context context.Context
+		Cube    Cube
+		Options Options
+		Errors  []error
+	}{
+		{"depth", context.Background(), near, Options{MaxDepth: 4}, []error{ErrDepthLimit, ErrNoSolution}},
+		{"BFS depth", context.Background(), near, Options{Metric: STM, MaxDepth: 2}, []error{ErrDepthLimit, ErrNoSolution}},
+		{"nodes", context.Background(), scrambled, Options{MaxNodes: 1000}, []error{ErrNodeLimit, ErrNoSolution}},
+		{"memory", context.Background(), near, Options{Metric: STM, MaxMemory: 1 << 20}, []error{ErrMemoryLimit, ErrNoSolution}},
+		{"canceled", canceled, scrambled, Options{}, []error{ErrCanceled, ErrNoSolution, context.Canceled}},
+		{"deadline", context.Background(), scrambled, Options{Deadline: time.Now().Add(100 * time.Millisecond)},
+			[]error{ErrCanceled, ErrNoSolution, context.DeadlineExceeded}},
+		{"timeout", context.Background(), scrambled, Options{Timeout: 100 * time.Millisecond},
+			[]error{ErrCanceled, ErrNoSolution, context.DeadlineExceeded}},
+	}
+	for _, tc := range testCases {
+		start := time.Now()
+		solved, err := SolveContext(tc.Context, tc.Cube, tc.Options)
+		var searchErr *SearchError
+		if solved != nil || !errors.As(err, &searchErr) {
+			t.Errorf("Search should stop on %s limit\nGot  %s %v", tc.Name, solved, err)
+			continue
+		}
+		for _, want := range tc.Errors {
+			if !errors.Is(err, want) {
+				t.Errorf("Wrong error on %s limit:\nGot  %v\nWant %s", tc.Name, err, want)
+			}
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Search should stop quickly on %s limit, took %s", tc.Name, elapsed)
+		}
+	}
+}
+
+func TestSolveContextInvalid(t *testing.T) {
+	cube := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	_, err := SolveContext(context.Background(), cube, Options{})
+	if !errors.Is(err, ErrInvalidCube) || errors.Is(err, ErrNoSolution) {
+		t.Errorf("Invalid cube should not be solved.\nGot  %v\nWant %s", err, ErrInvalidCube)
+	}
+}
+
+func TestSolveMoves(t *testing.T) {
+	testCases := []struct {
+		Scramble string
+		Moves    string
+		Metric   Metric
+		Length   int
+	}{
+		{"R U R' U'", "R U", HTM, 4},
+		{"R U R' U R U2 R'", "R U", HTM, 7},
+		{"R U2 F'", "R U F", HTM, 3},
+		{"U2 R", "R' U", QTM, 3},
+		{"M' U M U2", "M U", STM, 4},
+		{"R L'", "M U", STM, 1},
+		{"F D", "U D L R F", HTM, 2},
+	}
+	for _, tc := range testCases {
+		cube := MustParseAlgorithm(tc.Scramble).Apply(NewSolvedCube())
+		opts := Options{Metric: tc.Metric, Moves: parseMoves(tc.Moves)}
+		solved, err := SolveContext(context.Background(), cube, opts)
+		if err != nil || tc.Metric.Length(solved) != tc.Length || !solved.Apply(cube).IsSolved() {
+			t.Errorf("Wrong solution for %q with <%s> in %s\nGot  %s %v\nWant %d moves", tc.Scramble, tc.Moves, tc.Metric, solved, err, tc.Length)
+		}
+		allowed, _ := opts.allowedMoves()
+		for _, move := range solved {
+			found := false
+			for _, m := range allowed {
+				found = found || m == move
+			}
+			if !found {
+				t.Errorf("Solution for %q should only turn <%s>\nGot  %s", tc.Scramble, tc.Moves, solved)
+			}
+		}
+	}
+}
+
+func TestSolveMovesErrors(t *testing.T) {
+	testCases := []struct {
+		Scramble string
+		Moves    string
+		Metric   Metric
+		Error    error
+	}{
+		{"R U F", "R U", HTM, ErrUnreachable},
+		{"F D", "U R L F", HTM, ErrUnreachable},
+		{"R", "M U", STM, ErrUnreachable},
+		{"M", "M U", HTM, ErrUnsupportedMetric},
+		{"R", "R Q", HTM, ErrUnknownMove},
+		{"R", "", HTM, ErrUnsupportedMoves},
+	}
+	for _, tc := range testCases {
+		cube := MustParseAlgorithm(tc.Scramble).Apply(NewSolvedCube())
+		start := time.Now()
+		_, err := SolveContext(context.Background(), cube, Options{Metric: tc.Metric, Moves: parseMoves(tc.Moves)})
+		if !errors.Is(err, tc.Error) {
+			t.Errorf("Wrong error for %q with <%s> in %s:\nGot  %v\nWant %s", tc.Scramble, tc.Moves, tc.Metric, err, tc.Error)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Unreachable cube should be detected quickly, took %s", elapsed)
+		}
+	}
+}
+
+func TestSolveMovesGoals(t *testing.T) {
+	cube := MustParseAlgorithm("R U").Apply(NewSolvedCube())
+	moves := parseMoves("R U")
+	unreachable := MustParseAlgorithm("F").Apply(NewSolvedCube())
+	goals := []Goal{TargetGoal(unreachable), MaskedGoal(unreachable, FullMask())}
+	for name, metric := range map[string]Metric{"ida": HTM, "bfs": STM} {
+		solver, _ := LookupSolver(name)
+		for _, goal := range goals {
+			start := time.Now()
+			_, err := solver.Solve(context.Background(), cube, Options{Metric: metric, Moves: moves, Goal: goal})
+			if !errors.Is(err, ErrUnreachable) {
+				t.Errorf("Wrong error for an unreachable target with %s:\nGot  %v\nWant %s", name, err, ErrUnreachable)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Unreachable target should be detected quickly by %s, took %s", name, elapsed)
+			}
+		}
+	}
+	for _, err := range Solutions(context.Background(), cube, Options{Moves: moves, Goal: goals[0]}, 0) {
+		if !errors.Is(err, ErrUnreachable) {
+			t.Errorf("Wrong error for the solutions of an unreachable target:\nGot  %v\nWant %s", err, ErrUnreachable)
+		}
+	}
+
+	reachable := MustParseAlgorithm("R U R'").Apply(NewSolvedCube())
+	solved, err := SolveContext(context.Background(), cube, Options{Moves: moves, Goal: TargetGoal(reachable)})
+	if err != nil || solved.String() != "R'" {
+		t.Errorf("Wrong solution to a reachable target:\nGot  %s %v\nWant R'", solved, err)
+	}
+}
+
+func TestAllowedMoves(t *testing.T) {
+	testCases := []struct {
+		Moves    string
+		Metric   Metric
+		Expected string
+	}{
+		{"R U", HTM, "U U' R R' U2 R2"},
+		{"R2 U'", QTM, "U U' R R'"},
+		{"M U", STM, "U U' U2 M M' M2"},
+	}
+	for _, tc := range testCases {
+		moves, err := Options{Metric: tc.Metric, Moves: parseMoves(tc.Moves)}.allowedMoves()
+		if err != nil || Algorithm(moves).String() != tc.Expected {
+			t.Errorf("Wrong moves for <%s> in %s:\nGot  %s %v\nWant %s", tc.Moves, tc.Metric, Algorithm(moves), err, tc.Expected)
+		}
+	}
+	if moves, _ := (Options{Metric: STM}).allowedMoves(); len(moves) != len(STM.Moves()) {
+		t.Errorf("All the moves of the metric should be allowed by default:\nGot  %s", Algorithm(moves))
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	testCases := []struct {
+		Err      *SearchError
+		Expected string
+	}{
+		{&SearchError{ErrNodeLimit, nil, 1000}, "rubik: maximum number of explored cubes reached after 1000 cubes"},
+		{&SearchError{ErrCanceled, context.Canceled, 5}, "rubik: search canceled after 5 cubes: context canceled"},
+	}
+	for _, tc := range testCases {
+		if got := tc.Err.Error(); got != tc.Expected {
+			t.Errorf("Wrong error message:\nGot  %s\nWant %s", got, tc.Expected)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package rubik
+
+import (
+	"context"
+	"iter"
+)
+
+// Enumeration of solutions.
+//
+// Solvers return the first optimal solution they find, among possibly many. Solutions streams
+// all the solutions of up to a given number of moves more than optimal, shortest first, so that
+// callers can choose among them: the easiest to execute, or the one ending with a given move.
+//
+// Solutions are de-duplicated as the searches explore move sequences: turns of opposite faces,
+// or of layers around the same axis, commute and are only streamed in one order, the order of
+// the moves of the metric. Turning the same layer twice in a row, and going through a cube
+// reaching the goal before the last move, only make longer versions of other solutions: such
+// sequences are not streamed. In the quarter turn metric, half turns are only streamed as two
+// clockwise quarter turns, X X rather than X' X'.
+
+// Stream the solutions of the cube of at most `extra` moves more than an optimal solution,
+// in the metric of the options, by increasing length. Breaking out of the loop stops the search.
+//
+// Solutions are searched with IDA* in the half turn and quarter turn metrics, and with a
+// depth-first search without heuristic in the other metrics, only fast for cubes a few moves
+// away from the goal. The options limit the search as in SolveContext, Workers apart: the
+// search runs in the calling goroutine. If the cube is invalid, or a limit is reached, the
+// iteration ends with a nil solution and the error.
+func Solutions(ctx context.Context, cube Cube, opts Options, extra int) iter.Seq2[Algorithm, error] {
+	return func(yield func(Algorithm, error) bool) {
+		solver := *solutionsSolver
+		solver.search = func(cube Cube, c CubieCube, opts Options, budget *searchBudget) (Algorithm, error) {
+			return nil, enumerateSolutions(cube, c, opts, extra, budget, func(solution Algorithm) bool {
+				return yield(solution, nil)
+			})
+		}
+		if _, err := solver.Solve(ctx, cube, opts); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// Solver behind Solutions, whose search is set for each enumeration.
+var solutionsSolver = &searchSolver{
+	name:         "solutions",
+	capabilities: Capabilities{Optimal: true, Goals: true, Moves: true, Metrics: Metrics},
+	prepare: func(opts Options) {
+		if opts.Metric == HTM || opts.Metric == QTM {
+			opts.Heuristic.Estimate(NewSolvedCubieCube())
+		}
+	},
+}
+
+// Call `found` with each solution of the cube of at most `extra` moves more than optimal,
+// and stop as soon as it returns `false`. Return the error of the budget if the search had
+// to stop, or if no solution was found.
+func enumerateSolutions(cube Cube, c CubieCube, opts Options, extra int, budget *searchBudget, found func(Algorithm) bool) error {
+	var enumerate func(bound int, found func(Algorithm) bool) bool
+	first := 0
+	if opts.Metric == HTM || opts.Metric == QTM {
+		s := newIDASearch(opts.Moves, opts.Goal, opts.Heuristic, budget)
+		enumerate = func(bound int, found func(Algorithm) bool) bool {
+			return s.enumerate(c, bound, found)
+		}
+		first = opts.Heuristic.Estimate(c)
+	} else {
+		s := newDFSSearch(opts.Moves, opts.Goal, budget)
+		enumerate = func(bound int, found func(Algorithm) bool) bool {
+			return s.enumerate(cube, bound, found)
+		}
+	}
+
+	optimal := -1
+	for bound := first; bound <= opts.maxDepth(MAX_IDA_DEPTH); bound++ {
+		if optimal >= 0 && bound > optimal+extra {
+			return nil
+		}
+		count := 0
+		if !enumerate(bound, func(solution Algorithm) bool {
+			count++
+			return found(solution)
+		}) {
+			return budget.err
+		}
+		if count > 0 && optimal < 0 {
+			optimal = bound
+		}
+	}
+	if optimal < 0 {
+		return budget.failure()
+	}
+	return nil
+}
+
+// Call `found` with each path of exactly `bound` more moves reaching the goal, but not before
+// its last move. Return `false` as soon as `found` does, or if the search must stop.
+func (s *idaSearch) enumerate(c CubieCube, bound int, found func(Algorithm) bool) bool {
+	if s.isReached(c) {
+		return bound > 0 || found(append(Algorithm{}, s.path...))
+	}
+	if bound == 0 || !s.budget.visit() || s.heuristic.Estimate(c) > bound {
+		return s.budget.err == nil
+	}
+	for _, move := range s.moves {
+		if isRedundant(s.path, move, s.quarterTurns) {
+			continue
+		}
+		s.path = append(s.path, move)
+		ok := s.enumerate(c.Multiply(cubieMoves[move]), bound-1, found)
+		s.path = s.path[:len(s.path)-1]
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// State of a depth-first search turning facelets, for moves IDA* does not support.
+type dfsSearch struct {
+	moveTable
+	goal   Goal
+	path   []int // Index of each move of the current sequence
+	budget *searchBudget
+}
+
+// Build a search allowing the given moves, reaching the given goal (solved cubes if nil).
+func newDFSSearch(moves []Move, goal Goal, budget *searchBudget) *dfsSearch {
+	if goal == nil {
+		goal = GoalFunc(Cube.IsSolved)
+	}
+	return &dfsSearch{moveTable: newMoveTable(moves), goal: goal, budget: budget}
+}
+
+// Call `found` with each path of exactly `bound` more moves reaching the goal, but not before
+// its last move. Return `false` as soon as `found` does, or if the search must stop.
+func (s *dfsSearch) enumerate(cube Cube, bound int, found func(Algorithm) bool) bool {
+	if s.goal.IsReached(cube) {
+		return bound > 0 || found(s.algorithm())
+	}
+	if bound == 0 || !s.budget.visit() {
+		return s.budget.err == nil
+	}
+	for j := range s.moves {
+		if len(s.path) > 0 && s.isRedundantAfter(s.path[len(s.path)-1], j) {
+			continue
+		}
+		s.path = append(s.path, j)
+		ok := s.enumerate(cube.permute(s.permutations[j]), bound-1, found)
+		s.path = s.path[:len(s.path)-1]
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Moves of the current sequence.
+func (s *dfsSearch) algorithm() Algorithm {
+	alg := make(Algorithm, len(s.path))
+	for i, j := range s.path {
+		alg[i] = s.moves[j]
+	}
+	return alg
+}
@@ -0,0 +1,139 @@
+package rubik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Solutions streamed for the cube, and the error ending the stream.
+func collectSolutions(cube Cube, opts Options, extra int) ([]string, error) {
+	solutions := []string{}
+	for solution, err := range Solutions(context.Background(), cube, opts, extra) {
+		if err != nil {
+			return solutions, err
+		}
+		solutions = append(solutions, solution.String())
+	}
+	return solutions, nil
+}
+
+func TestSolutions(t *testing.T) {
+	testCases := []struct {
+		Scramble string
+		Metric   Metric
+		Extra    int
+		Expected []string
+	}{
+		{"", HTM, 2, []string{""}},
+		{"R L", HTM, 0, []string{"L' R'"}},
+		{"R2 L2 U2 D2 F2 B2", HTM, 0, []string{
+			"U2 D2 L2 R2 F2 B2", "U2 D2 F2 B2 L2 R2", "L2 R2 U2 D2 F2 B2",
+			"L2 R2 F2 B2 U2 D2", "F2 B2 U2 D2 L2 R2", "F2 B2 L2 R2 U2 D2",
+		}},
+		{"U2 R", QTM, 0, []string{"R' U U"}},
+		{"M", STM, 1, []string{"M'", "L R'", "L Lw'", "R' Rw", "Lw' Rw"}},
+	}
+	for _, tc := range testCases {
+		cube := MustParseAlgorithm(tc.Scramble).Apply(NewSolvedCube())
+		solutions, err := collectSolutions(cube, Options{Metric: tc.Metric}, tc.Extra)
+		if err != nil || fmt.Sprint(solutions) != fmt.Sprint(tc.Expected) {
+			t.Errorf("Wrong solutions for %q in %s:\nGot  %q %v\nWant %q", tc.Scramble, tc.Metric, solutions, err, tc.Expected)
+		}
+	}
+}
+
+func TestSolutionsExtra(t *testing.T) {
+	s := NewScrambler(3)
+	for i := 0; i < 5; i++ {
+		scramble, cube, _ := s.RandomMoves(4)
+		want, _ := SolveMetric(cube, HTM)
+		seen := map[string]bool{}
+		previous := 0
+		for solution, err := range Solutions(context.Background(), cube, Options{}, 2) {
+			if err != nil {
+				t.Errorf("Error while enumerating the solutions of %s: %v", scramble, err)
+				break
+			}
+			if !solution.Apply(cube).IsSolved() || len(solution) < previous || len(solution) > len(want)+2 || seen[solution.String()] {
+				t.Errorf("Wrong solution for %s after %d moves:\nGot  %s\nWant %d to %d moves", scramble, previous, solution, len(want), len(want)+2)
+			}
+			seen[solution.String()] = true
+			previous = len(solution)
+		}
+	}
+}
+
+func TestSolutionsQuarterTurnsUnique(t *testing.T) {
+	s := NewScrambler(7)
+	for i := 0; i < 5; i++ {
+		scramble, cube, _ := s.RandomMoves(4)
+		// Solutions only differing by the direction of two quarter turns simplify the same
+		seen := map[string]string{}
+		for solution, err := range Solutions(context.Background(), cube, Options{Metric: QTM}, 1) {
+			if err != nil {
+				t.Errorf("Error while enumerating the solutions of %s: %v", scramble, err)
+				break
+			}
+			simplified := solution.Simplify().String()
+			if other, found := seen[simplified]; found {
+				t.Errorf("Solutions of %s should be unique:\nGot  %s\nWant other than %s", scramble, solution, other)
+			}
+			seen[simplified] = solution.String()
+		}
+	}
+}
+
+func TestSolutionsSolver(t *testing.T) {
+	for _, scramble := range []string{"F", "F U", "R2 F", "F U R", "F R2 U L"} {
+		cube := MustParseAlgorithm(scramble).Apply(NewSolvedCube())
+		solved, _ := Solve(cube)
+		solutions, err := collectSolutions(cube, Options{}, 0)
+		found := false
+		for _, solution := range solutions {
+			found = found || solution == solved.String()
+		}
+		if err != nil || !found {
+			t.Errorf("Solution of %s should be streamed:\nGot  %q %v\nWant %s", scramble, solutions, err, solved)
+		}
+	}
+}
+
+func TestSolutionsGoal(t *testing.T) {
+	target := NewSolvedCube().R()
+	solutions, err := collectSolutions(NewSolvedCube(), Options{Goal: TargetGoal(target)}, 0)
+	if err != nil || fmt.Sprint(solutions) != "[R]" {
+		t.Errorf("Wrong solutions reaching the target:\nGot  %q %v\nWant [R]", solutions, err)
+	}
+}
+
+func TestSolutionsStop(t *testing.T) {
+	cube := MustParseAlgorithm("R2 L2 U2 D2 F2 B2").Apply(NewSolvedCube())
+	count := 0
+	for _, err := range Solutions(context.Background(), cube, Options{}, 0) {
+		count++
+		if err != nil || count > 1 {
+			t.Errorf("Enumeration should stop after the first solution:\nGot  %d solutions %v", count, err)
+		}
+		break
+	}
+}
+
+func TestSolutionsErrors(t *testing.T) {
+	cube := MustParseAlgorithm("R U F' L2 D").Apply(NewSolvedCube())
+	if _, err := collectSolutions(cube, Options{MaxDepth: 4}, 0); !errors.Is(err, ErrDepthLimit) {
+		t.Errorf("Enumeration should stop at the maximum depth:\nGot  %v\nWant %s", err, ErrDepthLimit)
+	}
+	if _, err := collectSolutions(cube, Options{Metric: STM, MaxNodes: 100}, 0); !errors.Is(err, ErrNodeLimit) {
+		t.Errorf("Enumeration should stop after the maximum number of cubes:\nGot  %v\nWant %s", err, ErrNodeLimit)
+	}
+	solutions, err := collectSolutions(MustParseAlgorithm("R").Apply(NewSolvedCube()), Options{MaxNodes: 10}, 4)
+	if !errors.Is(err, ErrNodeLimit) || fmt.Sprint(solutions) != "[R']" {
+		t.Errorf("Enumeration should stream solutions until the maximum number of cubes:\nGot  %q %v\nWant [R'] %s", solutions, err, ErrNodeLimit)
+	}
+	invalid := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	if _, err := collectSolutions(invalid, Options{}, 0); !errors.Is(err, ErrInvalidCube) {
+		t.Errorf("Invalid cube should not be solved:\nGot  %v\nWant %s", err, ErrInvalidCube)
+	}
+}
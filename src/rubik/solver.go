@@ -1,91 +1,239 @@
 package rubik
 
 import (
-	"fmt"
+	"context"
 )
 
-// A naive solver using BFS.
+// A solver using BFS.
 //
-// Because the resolution space is very large, this solver will only work on slightly
-// scrambled cubes (3-4 moves away from a solved cube), even though cycles are detected
-// and eliminated. Beyond that, it will only consume time and fail with insufficient memory.
+// Cubes are explored by increasing number of moves from the scrambled cube. Each cube is only
+// explored once, through a set of visited cubes keyed by a compact encoding, and keeps a link
+// to the cube it was reached from to rebuild the solution. Moves that cannot lead to unvisited
+// cubes, such as turning the same layer twice in a row, are skipped. Memory still grows
+// exponentially with the depth: this solver only works on cubes about 6 moves away from
+// solved. It is used for metrics counting slice turns, that IDA* does not support.
 //
 // See smarter algorithms:
 // https://en.wikipedia.org/wiki/Optimal_solutions_for_Rubik%27s_Cube
 // "Algorithms for solving the Rubik's cube" - Harpreet Kaur (in this repo)
 
-// Candidate solution, expressed as a succession of cubes and the corresponding moves
-// to transition from the initial state to the current state (or solution).
-type Vertex struct {
-	Path  []Cube
-	Moves []Move
+// Approximate memory used by each cube explored by the BFS, in bytes: its node,
+// and its entry in the visited set.
+const BFS_NODE_SIZE = 96
+
+// (Attempt to) solve the given cube, and return a list of moves.
+// The solution is optimal in the half turn metric.
+//
+// Return an ErrInvalidCube (as a *ValidationError) if the cube cannot be solved,
+// or ErrNoSolution if the search space was exhausted before finding a solution.
+func Solve(cube Cube) (Algorithm, error) {
+	return SolveMetric(cube, HTM)
 }
 
-// Create a new vertex.
-func NewVertex(path []Cube, moves []Move) *Vertex {
-	return &Vertex{path, moves}
+// Like Solve, but return a solution that is optimal in the given metric.
+//
+// Cubes are solved with IDA* in the half turn and quarter turn metrics, and with a BFS
+// in the other metrics. See SolveContext to limit the search.
+func SolveMetric(cube Cube, metric Metric) (Algorithm, error) {
+	return SolveContext(context.Background(), cube, Options{Metric: metric})
 }
 
-// String representation of the solution.
-func (vertex Vertex) String() string {
-	s := "["
-	for i, move := range vertex.Moves {
-		s = s + string(move)
-		if i != len(vertex.Moves)-1 {
-			s = s + ", "
+// Solve the cube using a BFS, allowing the given moves only, reaching the given goal (solved
+// cubes if nil), and looking for solutions of at most `maxDepth` moves with the given number
+// of workers.
+func solveBFS(cube Cube, moves []Move, goal Goal, maxDepth, workers int, budget *searchBudget) (Algorithm, error) {
+	if goal == nil {
+		goal = GoalFunc(Cube.IsSolved)
+	}
+	if goal.IsReached(cube) {
+		return Algorithm{}, nil
+	}
+	t := newBFSTree(cube, moves, cube)
+	t.workers = workers
+	budget.allocate(BFS_NODE_SIZE)
+	for depth, start := 0, 0; depth < maxDepth && start < len(t.nodes); depth++ {
+		end := len(t.nodes)
+		node, err := t.expand(start, budget, func(key cubeKey, cube Cube) bool {
+			return goal.IsReached(cube)
+		})
+		if err != nil {
+			return nil, err
 		}
+		if node >= 0 {
+			return t.path(node), nil
+		}
+		start = end
 	}
-	s = s + "]"
-	return s
+	return nil, budget.failure()
 }
 
-// Check if the given cube in contained in this path (as vertex).
-func (vertex Vertex) Contains(cube Cube) bool {
-	for _, c := range vertex.Path {
-		if c.Equals(cube) {
-			return true
-		}
+// Cubes explored by a BFS, each of them linked to the cube it was reached from.
+type bfsTree struct {
+	moveTable
+	workers int // Goroutines expanding the layers, see expandParallel
+
+	colors  [256]uint8 // Index of each color, from 0 to 5
+	palette [6]byte    // Color of each index
+
+	nodes   []bfsNode
+	visited map[cubeKey]int32 // Index of the node of each visited cube
+}
+
+// Node of a BFS tree: a cube, and the move leading to it from its parent.
+type bfsNode struct {
+	key    cubeKey
+	parent int32 // Index of the parent node, -1 for the root
+	move   uint8 // Index of the move in the moves of the tree
+}
+
+// Cube packed in 162 bits: 3 bits for the color index of each facelet.
+type cubeKey [3]uint64
+
+// Build a tree holding the given roots only. Their children are reached with the given moves.
+// Cubes are encoded using the colors of the centers of `colors`, so that trees sharing them
+// also share the encoding of cubes.
+func newBFSTree(colors Cube, moves []Move, roots ...Cube) *bfsTree {
+	t := &bfsTree{moveTable: newMoveTable(moves), visited: map[cubeKey]int32{}}
+	for i, center := range centerFacelets {
+		t.colors[colors[center]] = uint8(i)
+		t.palette[i] = colors[center]
 	}
-	return false
+	for _, root := range roots {
+		t.add(t.key(root), -1, 0)
+	}
+	return t
 }
 
-// Add a cube state and the corresponding move to this vertex.
-func (vertex Vertex) Add(cube Cube, move Move) *Vertex {
-	newPath := append(vertex.Path, cube)
-	newMoves := append(vertex.Moves, move)
-	return NewVertex(newPath, newMoves)
+// Add a cube to the tree, reached from the given node with the given move.
+// Return `false` if the cube was already visited.
+func (t *bfsTree) add(key cubeKey, parent, move int) bool {
+	if _, found := t.visited[key]; found {
+		return false
+	}
+	t.visited[key] = int32(len(t.nodes))
+	t.nodes = append(t.nodes, bfsNode{key, int32(parent), uint8(move)})
+	return true
 }
 
-// Return the last cube state from this vertex.
-func (vertex Vertex) LastCube() Cube {
-	return vertex.Path[len(vertex.Path)-1]
+// Compact encoding of a cube.
+func (t *bfsTree) key(cube Cube) cubeKey {
+	key := cubeKey{}
+	for i, color := range cube {
+		key[i/21] |= uint64(t.colors[color]) << (3 * (i % 21))
+	}
+	return key
 }
 
-// (Attempt to) solve the given cube, and return a list of moves.
-func Solve(cube Cube) []Move {
-	origin := NewVertex([]Cube{cube}, []Move{})
-	q := make(chan *Vertex, 100000000)
-	q <- origin
-
-	for {
-		vertex := <-q
-		lastCube := vertex.LastCube()
-
-		for _, move := range Moves {
-			newCube := lastCube.Turn(move)
-			if vertex.Contains(newCube) {
+// Cube of the given node.
+func (t *bfsTree) cube(node int) Cube {
+	cube := Cube{}
+	key := t.nodes[node].key
+	for i := range cube {
+		cube[i] = t.palette[key[i/21]>>(3*(i%21))&7]
+	}
+	return cube
+}
+
+// Add the children of the nodes from `start` to the end of the tree, aka its last layer.
+// Stop as soon as a new node reaches the goal, and return its index, or -1 if none does.
+// Return the error of the budget if the search must stop.
+func (t *bfsTree) expand(start int, budget *searchBudget, goal func(key cubeKey, cube Cube) bool) (int, error) {
+	if t.workers > 1 {
+		return t.expandParallel(start, budget, goal)
+	}
+	for i, end := start, len(t.nodes); i < end; i++ {
+		c := t.cube(i)
+		for j := range t.moves {
+			if t.isRedundant(i, j) {
 				continue
 			}
-
-			newVertex := vertex.Add(newCube, move)
-			if newCube.IsSolved() {
-				fmt.Printf("Found a solution: %s\n", newVertex.Moves)
-				return newVertex.Moves
-				panic("No")
+			if !budget.visit() {
+				return -1, budget.err
+			}
+			next := c.permute(t.permutations[j])
+			key := t.key(next)
+			if !t.add(key, i, j) {
+				continue
+			}
+			if goal(key, next) {
+				return len(t.nodes) - 1, nil
+			}
+			if !budget.allocate(BFS_NODE_SIZE) {
+				return -1, budget.err
 			}
-			q <- newVertex
 		}
 	}
+	return -1, nil
+}
+
+// Moves leading from the root to the given node.
+func (t *bfsTree) path(node int) Algorithm {
+	alg := Algorithm{}
+	for ; t.nodes[node].parent >= 0; node = int(t.nodes[node].parent) {
+		alg = append(alg, t.moves[t.nodes[node].move])
+	}
+	for i, j := 0, len(alg)-1; i < j; i, j = i+1, j-1 {
+		alg[i], alg[j] = alg[j], alg[i]
+	}
+	return alg
+}
+
+// Return `true` if the move cannot lead to a cube that is not visited yet, or if an
+// equivalent sequence of moves of the same length is explored instead.
+func (t *bfsTree) isRedundant(node, move int) bool {
+	return t.nodes[node].parent >= 0 && t.isRedundantAfter(int(t.nodes[node].move), move)
+}
 
-	panic("unreachable statement reached")
+// Moves of a search turning facelets, with the layers they turn.
+type moveTable struct {
+	moves        []Move
+	permutations []*Permutation
+	layers       []string // Layer turned by each move
+	amounts      []int    // Quarter turns of each move: 1, 2 or 3 (counterclockwise)
+	moveSet      map[Move]bool
+}
+
+// Build the table of the given moves.
+func newMoveTable(moves []Move) moveTable {
+	m := moveTable{
+		moves:        moves,
+		permutations: make([]*Permutation, len(moves)),
+		layers:       make([]string, len(moves)),
+		amounts:      make([]int, len(moves)),
+		moveSet:      map[Move]bool{},
+	}
+	for i, move := range moves {
+		m.permutations[i] = movePermutations[move]
+		m.layers[i], m.amounts[i] = splitMove(move)
+		m.moveSet[move] = true
+	}
+	return m
+}
+
+// Return `true` if the move never needs to follow the previous one, as the same cube is
+// reached with fewer moves, or with an equivalent sequence of moves of the same length.
+//
+// Turning the same layer twice in a row reaches a visited cube, when the combined turn is
+// one of the moves. Turns of different layers around the same axis commute, and are only
+// explored in alphabetical order of their layers.
+func (m *moveTable) isRedundantAfter(previous, move int) bool {
+	layer, previousLayer := m.layers[move], m.layers[previous]
+	if layer == previousLayer {
+		amount := (m.amounts[move] + m.amounts[previous]) % 4
+		return amount == 0 || m.moveSet[turnOf(layer, amount)]
+	}
+	axis := axisOf(layer)
+	return axis >= 'x' && axis <= 'z' && axis == axisOf(previousLayer) && layer < previousLayer
+}
+
+// Move turning the layer by the given number of quarter turns: 1, 2 or 3 (counterclockwise).
+func turnOf(layer string, amount int) Move {
+	switch amount {
+	case 2:
+		return Move(layer + "2")
+	case 3:
+		return Move(layer + "'")
+	default:
+		return Move(layer)
+	}
 }
@@ -1,6 +1,8 @@
 package rubik
 
 import (
+	"context"
+	"errors"
 	"testing"
 )
 
@@ -15,16 +17,19 @@ func TestSolver(t *testing.T) {
 		&testCase{NewSolvedCube().Fc(), []Move{FRONT}},
 		&testCase{NewSolvedCube().U(), []Move{UP_COUNTER}},
 		&testCase{NewSolvedCube().F().U(), []Move{UP_COUNTER, FRONT_COUNTER}},
-		&testCase{NewSolvedCube().U().U(), []Move{UP, UP}},
+		&testCase{NewSolvedCube().U().U(), []Move{UP_HALF}},
+		&testCase{NewSolvedCube().R2().F(), []Move{FRONT_COUNTER, RIGHT_HALF}},
 		&testCase{NewSolvedCube().F().U().R(), []Move{RIGHT_COUNTER, UP_COUNTER, FRONT_COUNTER}},
-
-		// Too complex for current naive solver
-		// &testCase{NewSolvedCube().F().R().R().U().L(), []Move{RIGHT_COUNTER, UP_COUNTER, FRONT_COUNTER}},
-		// &testCase{NewCube("bwwbwwyyr orwogbygb gbbrrwrrw ooygbygbr oogoogyyb rrwgywgyo"), []Move{RIGHT_COUNTER, UP_COUNTER, FRONT_COUNTER}},
+		&testCase{NewSolvedCube().F().R().R().U().L(), []Move{LEFT_COUNTER, UP_COUNTER, RIGHT_HALF, FRONT_COUNTER}},
+		&testCase{MustParseCube("bwwbwwyyr orwogbygb gbbrrwrrw ooygbygbr oogoogyyb rrwgywgyo"), []Move{LEFT_COUNTER, UP_COUNTER, RIGHT_HALF, FRONT_COUNTER}},
 	}
 
 	for _, tc := range testCases {
-		solved := Solve(tc.Cube)
+		solved, err := Solve(tc.Cube)
+		if err != nil {
+			t.Errorf("Error while solving %s: %s", tc.Cube, err)
+			continue
+		}
 
 		if len(tc.Expected) != len(solved) {
 			t.Errorf("Error while solving. Incompatible solution sizes.\nGot  :%s\nWant :%s\n", solved, tc.Expected)
@@ -38,3 +43,117 @@ func TestSolver(t *testing.T) {
 		}
 	}
 }
+
+func TestSolveFunnyLooking(t *testing.T) {
+	cube := MustParseCube("ggggwgggg rrrrgrrrr wwwwrwwww ooooboooo yyyyoyyyy bbbbybbbb")
+	solved, err := Solve(cube)
+	if err != nil || len(solved) != 8 || !solved.Apply(cube).IsSolved() {
+		t.Errorf("Error while solving %s\nGot  %s %v", cube, solved, err)
+	}
+}
+
+func TestSolveMetric(t *testing.T) {
+	cube := NewSolvedCube().U2().R()
+	solved, err := SolveMetric(cube, QTM)
+	expected := []Move{RIGHT_COUNTER, UP, UP}
+	if err != nil || QTM.Length(solved) != 3 || !solved.Apply(cube).IsSolved() {
+		t.Errorf("Error while solving in QTM.\nGot  %s %v\nWant %s", solved, err, expected)
+	}
+}
+
+func TestSolveAlreadySolved(t *testing.T) {
+	solved, err := Solve(NewSolvedCube())
+	if err != nil || len(solved) != 0 {
+		t.Errorf("Solved cube should need no move.\nGot  %s %v", solved, err)
+	}
+}
+
+func TestSolveInvalid(t *testing.T) {
+	cube := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	_, err := Solve(cube)
+	if !errors.Is(err, ErrInvalidCube) {
+		t.Errorf("Invalid cube should not be solved.\nGot  %v\nWant %s", err, ErrInvalidCube)
+	}
+}
+
+func TestBFSTreeKey(t *testing.T) {
+	s := NewScrambler(1)
+	moves := STM.Moves()
+	for i := 0; i < 20; i++ {
+		_, cube, _ := s.RandomMoves(10, moves...)
+		tree := newBFSTree(cube, moves, cube)
+		if got := tree.cube(0); got != cube {
+			t.Errorf("Wrong cube decoded from its key:\nGot  %s\nWant %s", got, cube)
+		}
+		next := cube.MustTurn(MIDDLE)
+		if !tree.add(tree.key(next), 0, 0) || tree.add(tree.key(next), 0, 1) {
+			t.Errorf("Cube should be added only once: %s", next)
+		}
+		if got := tree.cube(1); got != next {
+			t.Errorf("Wrong cube decoded from its key:\nGot  %s\nWant %s", got, next)
+		}
+	}
+}
+
+func TestBFSTreePath(t *testing.T) {
+	moves := Moves
+	tree := newBFSTree(NewSolvedCube(), moves, NewSolvedCube())
+	node := 0
+	for _, move := range MustParseAlgorithm("R U2 F'") {
+		for j, m := range moves {
+			if m == move {
+				tree.add(tree.key(tree.cube(node).MustTurn(move)), node, j)
+				node = len(tree.nodes) - 1
+			}
+		}
+	}
+	if got := tree.path(node).String(); got != "R U2 F'" {
+		t.Errorf("Wrong path:\nGot  %s\nWant R U2 F'", got)
+	}
+}
+
+func TestBFSTreeRedundant(t *testing.T) {
+	testCases := []struct {
+		Previous  Move
+		Move      Move
+		Moves     []Move
+		Redundant bool
+	}{
+		{RIGHT, RIGHT, Moves, true},
+		{RIGHT, RIGHT_COUNTER, Moves, true},
+		{RIGHT_HALF, RIGHT, Moves, true},
+		{RIGHT, RIGHT, QuarterTurns, false},
+		{RIGHT, RIGHT_COUNTER, QuarterTurns, true},
+		{RIGHT, LEFT, Moves, true},
+		{LEFT, RIGHT, Moves, false},
+		{RIGHT, UP, Moves, false},
+		{UP, RIGHT, Moves, false},
+		{RIGHT, MIDDLE, STM.Moves(), true},
+		{MIDDLE, RIGHT, STM.Moves(), false},
+		{RIGHT, RIGHT, []Move{RIGHT, UP}, false},
+	}
+	for _, tc := range testCases {
+		tree := newBFSTree(NewSolvedCube(), tc.Moves, NewSolvedCube())
+		index := map[Move]int{}
+		for j, move := range tc.Moves {
+			index[move] = j
+		}
+		tree.add(tree.key(NewSolvedCube().MustTurn(tc.Previous)), 0, index[tc.Previous])
+		if got := tree.isRedundant(1, index[tc.Move]); got != tc.Redundant {
+			t.Errorf("Wrong redundancy of %s after %s:\nGot  %v\nWant %v", tc.Move, tc.Previous, got, tc.Redundant)
+		}
+	}
+}
+
+func TestSolveBFS(t *testing.T) {
+	s := NewScrambler(2)
+	for i := 0; i < 5; i++ {
+		scramble, cube, _ := s.RandomMoves(5)
+		want, _ := SolveMetric(cube, HTM)
+		budget := &searchBudget{ctx: context.Background()}
+		solved, err := solveBFS(cube, Moves, nil, MAX_IDA_DEPTH, 1, budget)
+		if err != nil || len(solved) != len(want) || !solved.Apply(cube).IsSolved() {
+			t.Errorf("Solution for %s should be optimal\nGot  %s %v\nWant %s", scramble, solved, err, want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package rubik
+
+import (
+	"math/big"
+	"sync"
+)
+
+// Subgroups of the cube, generated by a restricted set of moves.
+//
+// Solving with some moves only, such as R and U for one-handed solves, reaches a small part
+// of the cube states: the subgroup generated by these moves. Searches for states out of the
+// subgroup would never end, so they are detected beforehand with the Schreier-Sims algorithm.
+//
+// The algorithm picks a base: a sequence of facelets, each of them moved by some permutations
+// fixing the previous ones. For each facelet of the base, it keeps the orbit of the facelet
+// under these permutations, with a permutation bringing the facelet to each position of its
+// orbit. A permutation is sifted through the base, undoing its effect on each facelet: it
+// belongs to the subgroup if it becomes the identity. The order of the subgroup is the
+// product of the sizes of the orbits.
+//
+// See https://en.wikipedia.org/wiki/Schreier%E2%80%93Sims_algorithm
+
+// Group of the permutations of the facelets performed by sequences of some moves.
+type Subgroup struct {
+	moves  []Move
+	levels []*subgroupLevel
+}
+
+// Facelet of the base of a subgroup, and its orbit under the permutations fixing the
+// previous facelets of the base.
+//
+// Permutations are used as functions here: `p` moves facelet `i` to `p[i]`. As inverses of
+// the permutations of the moves generate the same group, this does not change the subgroup.
+type subgroupLevel struct {
+	facelet     int
+	generators  []Permutation
+	transversal map[int]Permutation // Permutation moving the facelet to each position of its orbit
+}
+
+// Build the subgroup generated by the given moves, from Moves or the extended moves.
+//
+// Return an ErrUnknownMove (as a *ParseError) if a move is not supported.
+func NewSubgroup(moves ...Move) (*Subgroup, error) {
+	g := &Subgroup{moves: moves}
+	for _, move := range moves {
+		p, err := move.Permutation()
+		if err != nil {
+			return nil, err
+		}
+		if residue, _ := g.sift(p, 0); !residue.IsIdentity() {
+			g.extend(0, residue)
+		}
+	}
+	return g, nil
+}
+
+// Moves generating the subgroup.
+func (g *Subgroup) Moves() []Move {
+	return g.moves
+}
+
+// Number of permutations of the subgroup.
+func (g *Subgroup) Order() *big.Int {
+	order := big.NewInt(1)
+	for _, level := range g.levels {
+		order.Mul(order, big.NewInt(int64(len(level.transversal))))
+	}
+	return order
+}
+
+// Return `true` if the permutation is performed by some sequence of the moves of the subgroup.
+func (g *Subgroup) Contains(p Permutation) bool {
+	residue, _ := g.sift(p, 0)
+	return residue.IsIdentity()
+}
+
+// Return `true` if the moves of the subgroup can solve the cube, in any orientation when
+// they turn the centers.
+//
+// Return an ErrInvalidCube (as a *ValidationError) if the cube cannot be solved at all.
+func (g *Subgroup) IsSolvable(cube Cube) (bool, error) {
+	if err := cube.Validate(); err != nil {
+		return false, err
+	}
+	// The cube is the solved cube having its centers, once permuted by `p`. Moves `m` solve it
+	// if `p` then `m` is a rotation `o`, and `m = p⁻¹ o` then belongs to the subgroup.
+	solved := Cube{}
+	for face, center := range centerFacelets {
+		for i := 9 * face; i < 9*(face+1); i++ {
+			solved[i] = cube[center]
+		}
+	}
+	p := stickerPermutation(cube, solved)
+	for _, o := range orientations {
+		if g.Contains(p.Inverse().Then(o)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Return `true` if the moves of the subgroup can turn the cube `from` into the cube `to`,
+// in the orientation of `to`.
+//
+// Return the errors of Relabel if the cubes cannot be compared.
+func (g *Subgroup) canReach(from, to Cube) (bool, error) {
+	relabeled, err := Relabel(from, to)
+	if err != nil {
+		return false, err
+	}
+	return g.Contains(stickerPermutation(relabeled, NewSolvedCube()).Inverse()), nil
+}
+
+// Permutation sending each facelet of the cube to the facelet of `solved` with its sticker.
+func stickerPermutation(cube, solved Cube) Permutation {
+	positions := map[sticker]uint8{}
+	for i := range solved {
+		positions[solved.sticker(i)] = uint8(i)
+	}
+	p := Permutation{}
+	for i := range cube {
+		p[i] = positions[cube.sticker(i)]
+	}
+	return p
+}
+
+// Undo the effect of the permutation on the facelets of the base, from the given level.
+// Return what is left of the permutation, and the level where it stopped: the permutation
+// is out of the subgroup if the facelet of this level is moved out of its orbit.
+func (g *Subgroup) sift(p Permutation, start int) (Permutation, int) {
+	for i := start; i < len(g.levels); i++ {
+		level := g.levels[i]
+		t, found := level.transversal[int(p[level.facelet])]
+		if !found {
+			return p, i
+		}
+		p = t.Inverse().Then(p)
+	}
+	return p, len(g.levels)
+}
+
+// Add a permutation fixing the facelets of the base before the given level to the generators
+// of this level, and complete the levels after it, so that they stay a base of the subgroup.
+func (g *Subgroup) extend(i int, p Permutation) {
+	if i == len(g.levels) {
+		facelet := 0
+		for int(p[facelet]) == facelet {
+			facelet++
+		}
+		g.levels = append(g.levels, &subgroupLevel{facelet: facelet})
+	}
+	level := g.levels[i]
+	level.generators = append(level.generators, p)
+	level.transversal = map[int]Permutation{level.facelet: identity()}
+	orbit := []int{level.facelet}
+	for n := 0; n < len(orbit); n++ {
+		for _, s := range level.generators {
+			if next := int(s[orbit[n]]); !hasTransversal(level, next) {
+				level.transversal[next] = s.Then(level.transversal[orbit[n]])
+				orbit = append(orbit, next)
+			}
+		}
+	}
+	// Schreier generators: permutations fixing the facelet of this level, generating the
+	// permutations of the next levels.
+	for _, x := range orbit {
+		for _, s := range level.generators {
+			h := level.transversal[int(s[x])].Inverse().Then(s.Then(level.transversal[x]))
+			if residue, _ := g.sift(h, i+1); !residue.IsIdentity() {
+				g.extend(i+1, residue)
+			}
+		}
+	}
+}
+
+// Return `true` if the level has a permutation moving its facelet to the given position.
+func hasTransversal(level *subgroupLevel, position int) bool {
+	_, found := level.transversal[position]
+	return found
+}
+
+// Subgroups built by Solve, by set of moves.
+var subgroups = map[string]*Subgroup{}
+var subgroupsLock sync.Mutex
+
+// Return the subgroup generated by the given moves, built the first time it is needed.
+func subgroupOf(moves []Move) (*Subgroup, error) {
+	subgroupsLock.Lock()
+	defer subgroupsLock.Unlock()
+	key := Algorithm(moves).String()
+	if g, found := subgroups[key]; found {
+		return g, nil
+	}
+	g, err := NewSubgroup(moves...)
+	if err != nil {
+		return nil, err
+	}
+	subgroups[key] = g
+	return g, nil
+}
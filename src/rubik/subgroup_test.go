@@ -0,0 +1,120 @@
+package rubik
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Moves listed in the string, separated by spaces.
+func parseMoves(s string) []Move {
+	moves := []Move{}
+	for _, field := range strings.Fields(s) {
+		moves = append(moves, Move(field))
+	}
+	return moves
+}
+
+func TestSubgroupOrder(t *testing.T) {
+	testCases := []struct {
+		Moves string
+		Order string
+	}{
+		{"", "1"},
+		{"R2 U2", "12"},
+		{"M U", "184320"},
+		{"R U", "73483200"},
+		{"R2 L2 U2 D2 F2 B2", "663552"},
+		{"R U F", "170659735142400"},
+		{"U D L R F", "43252003274489856000"},
+		{"U D L R F B", "43252003274489856000"},
+		{"x y", "24"},
+	}
+	for _, tc := range testCases {
+		g, err := NewSubgroup(parseMoves(tc.Moves)...)
+		if err != nil || g.Order().String() != tc.Order {
+			t.Errorf("Wrong order of <%s>:\nGot  %v %v\nWant %s", tc.Moves, g.Order(), err, tc.Order)
+		}
+	}
+	if _, err := NewSubgroup(RIGHT, "Q"); !errors.Is(err, ErrUnknownMove) {
+		t.Errorf("Subgroup should not be generated by unknown moves:\nGot  %v\nWant %s", err, ErrUnknownMove)
+	}
+}
+
+func TestSubgroupContains(t *testing.T) {
+	g, _ := NewSubgroup(RIGHT, UP)
+	testCases := []struct {
+		Algorithm string
+		Contained bool
+	}{
+		{"", true},
+		{"R U R' U'", true},
+		{"R2 U' R' U' R U R U R U' R", true},
+		{"F", false},
+		{"R U F", false},
+		{"M", false},
+	}
+	for _, tc := range testCases {
+		p, _ := MustParseAlgorithm(tc.Algorithm).Permutation()
+		if got := g.Contains(p); got != tc.Contained {
+			t.Errorf("Wrong membership of %q in <R, U>:\nGot  %v\nWant %v", tc.Algorithm, got, tc.Contained)
+		}
+	}
+}
+
+func TestSubgroupIsSolvable(t *testing.T) {
+	testCases := []struct {
+		Moves    string
+		Scramble string
+		Solvable bool
+	}{
+		{"R U", "R U2 R' U'", true},
+		{"R U", "R U F", false},
+		{"R U", "x", true},
+		{"M U", "M' U2 M", true},
+		{"M U", "M' U2 M x", false},
+		{"M U", "R L'", true},
+		{"M U", "R", false},
+		{"U R L F", "F D", false},
+		{"U D L R F", "F B", true},
+	}
+	for _, tc := range testCases {
+		g, _ := NewSubgroup(parseMoves(tc.Moves)...)
+		cube := MustParseAlgorithm(tc.Scramble).Apply(NewSolvedCube())
+		if got, err := g.IsSolvable(cube); err != nil || got != tc.Solvable {
+			t.Errorf("Wrong solvability of %q with <%s>:\nGot  %v %v\nWant %v", tc.Scramble, tc.Moves, got, err, tc.Solvable)
+		}
+	}
+	g, _ := NewSubgroup(Moves...)
+	invalid := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	if _, err := g.IsSolvable(invalid); !errors.Is(err, ErrInvalidCube) {
+		t.Errorf("Invalid cube should not be solvable:\nGot  %v\nWant %s", err, ErrInvalidCube)
+	}
+}
+
+func TestSubgroupCanReach(t *testing.T) {
+	testCases := []struct {
+		Moves     string
+		From, To  string
+		Reachable bool
+	}{
+		{"R U", "R U", "R U R'", true},
+		{"R U", "", "F", false},
+		{"M U", "", "M U", true},
+		{"M U", "M' U2 M", "", true},
+		{"M U", "R L'", "", false}, // Solvable, but in another orientation
+	}
+	for _, tc := range testCases {
+		g, _ := NewSubgroup(parseMoves(tc.Moves)...)
+		from := MustParseAlgorithm(tc.From).Apply(NewSolvedCube())
+		to := MustParseAlgorithm(tc.To).Apply(NewSolvedCube())
+		if got, err := g.canReach(from, to); err != nil || got != tc.Reachable {
+			t.Errorf("Wrong reachability of %q from %q with <%s>:\nGot  %v %v\nWant %v", tc.To, tc.From, tc.Moves, got, err, tc.Reachable)
+		}
+	}
+	g, _ := NewSubgroup(Moves...)
+	invalid := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	if _, err := g.canReach(NewSolvedCube(), invalid); !errors.Is(err, ErrInvalidCube) {
+		t.Errorf("Invalid cube should not be reachable:\nGot  %v\nWant %s", err, ErrInvalidCube)
+	}
+}
@@ -0,0 +1,255 @@
+package rubik
+
+import (
+	"strings"
+	"sync"
+)
+
+// Thistlethwaite's algorithm.
+//
+// The cube is brought through nested subgroups, each of them using fewer moves than
+// the previous one:
+//
+//	G0 = <U, D, L, R, F, B>        any cube
+//	G1 = <U, D, L, R, F2, B2>      edges are oriented
+//	G2 = <U, D, L2, R2, F2, B2>    corners are oriented, UD slice edges are in their slice
+//	G3 = <U2, D2, L2, R2, F2, B2>  edges are in their slices, corners in their tetrads,
+//	                               and corners can be solved with half turns only
+//	G4 = {I}                       solved cube
+//
+// Phase i brings the cube from G(i-1) into G(i), using the moves of G(i-1). A lookup table
+// gives the number of moves needed for each coset of G(i), so that each phase is solved
+// optimally by always choosing a move that brings the cube one move closer.
+// https://www.jaapsch.net/puzzles/thistle.htm
+
+// Moves of each phase of Thistlethwaite's algorithm.
+type ThistlethwaiteSolution [4]Algorithm
+
+// All the moves, phase after phase.
+func (s ThistlethwaiteSolution) Algorithm() Algorithm {
+	alg := Algorithm{}
+	for _, phase := range s {
+		alg = append(alg, phase...)
+	}
+	return alg
+}
+
+// String representation, with the moves of each phase between parentheses,
+// such as "(F) (R U) () (U2)".
+func (s ThistlethwaiteSolution) String() string {
+	phases := make([]string, len(s))
+	for i, phase := range s {
+		phases[i] = "(" + phase.String() + ")"
+	}
+	return strings.Join(phases, " ")
+}
+
+// Solve the given cube using Thistlethwaite's algorithm, and return the moves of each phase.
+// Solutions are at most 45 moves long, and about 30 moves on average.
+//
+// Return an ErrInvalidCube (as a *ValidationError) if the cube cannot be solved.
+func SolveThistlethwaite(cube Cube) (ThistlethwaiteSolution, error) {
+	c, err := cube.ToCubie()
+	if err != nil {
+		return ThistlethwaiteSolution{}, err
+	}
+	return solveThistlethwaite(c), nil
+}
+
+// Solve the cube using Thistlethwaite's algorithm.
+func solveThistlethwaite(c CubieCube) ThistlethwaiteSolution {
+	solution := ThistlethwaiteSolution{}
+	for i, phase := range thistlethwaitePhases {
+		solution[i], c = phase.solve(c)
+	}
+	return solution
+}
+
+// Phase of Thistlethwaite's algorithm.
+type thistlethwaitePhase struct {
+	moves []Move
+
+	// Number of moves needed to bring a cube into the target subgroup.
+	table interface {
+		distance(c *CubieCube) int
+	}
+}
+
+// Moves of G1.
+var g1Moves = []Move{
+	UP, UP_COUNTER, DOWN, DOWN_COUNTER, LEFT, LEFT_COUNTER, RIGHT, RIGHT_COUNTER,
+	UP_HALF, DOWN_HALF, LEFT_HALF, RIGHT_HALF, FRONT_HALF, BACK_HALF,
+}
+
+var thistlethwaitePhases = [4]*thistlethwaitePhase{
+	// Orient the edges
+	{Moves, &pruningTable{coords: []*coordinate{flipCoord}}},
+	// Orient the corners, and bring the UD slice edges into their slice
+	{g1Moves, &pruningTable{coords: []*coordinate{
+		{size: twistCoord.size, get: twistCoord.get, moves: g1Moves},
+		{size: sliceCoord.size, get: sliceCoord.get, moves: g1Moves},
+	}}},
+	// Bring the edges into their slices, and the corners into a permutation solvable
+	// with half turns
+	{phase2Moves, &cosetTable{moves: phase2Moves, id: halfTurnsId}},
+	// Solve the cube
+	{HalfTurns, &pruningTable{coords: []*coordinate{
+		{size: 96, get: halfTurnCornersIndex, moves: HalfTurns},
+		{size: 24, get: slicePermutation(UF, UB, DF, DB), moves: HalfTurns},
+		{size: 24, get: slicePermutation(UR, UL, DR, DL), moves: HalfTurns},
+		{size: 24, get: slicePermutation(FR, FL, BL, BR), moves: HalfTurns},
+	}}},
+}
+
+// Bring the cube into the target subgroup with as few moves as possible.
+// Return these moves, and the resulting cube.
+func (phase *thistlethwaitePhase) solve(c CubieCube) (Algorithm, CubieCube) {
+	alg := Algorithm{}
+	for depth := phase.table.distance(&c); depth > 0; depth-- {
+		for _, move := range phase.moves {
+			next := c.Multiply(cubieMoves[move])
+			if phase.table.distance(&next) == depth-1 {
+				alg = append(alg, move)
+				c = next
+				break
+			}
+		}
+	}
+	return alg, c
+}
+
+// Number of moves needed to bring a cube into a subgroup, for each coset of this subgroup.
+type cosetTable struct {
+	moves []Move
+
+	// Identify the coset of the subgroup the cube belongs to: two cubes have the same
+	// id if and only if the same moves bring them both into the subgroup.
+	id func(c *CubieCube) uint64
+
+	once   sync.Once
+	depths map[uint64]int8
+}
+
+// Number of moves needed to bring the cube into the subgroup.
+func (t *cosetTable) distance(c *CubieCube) int {
+	t.once.Do(t.init)
+	return int(t.depths[t.id(c)])
+}
+
+// Build the table using a BFS from the solved cube.
+// A cube is kept for each coset found, to compute the effect of the moves on it.
+func (t *cosetTable) init() {
+	solved := NewSolvedCubieCube()
+	t.depths = map[uint64]int8{t.id(&solved): 0}
+	queue := []CubieCube{solved}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		depth := t.depths[t.id(&c)]
+		for _, move := range t.moves {
+			next := c.Multiply(cubieMoves[move])
+			id := t.id(&next)
+			if _, found := t.depths[id]; !found {
+				t.depths[id] = depth + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+}
+
+// Which slice each edge belongs to, and the permutation of the corners up to
+// the permutations of the corners reachable with half turns.
+func halfTurnsId(c *CubieCube) uint64 {
+	id := uint64(0)
+	for _, edge := range c.Ep {
+		id = id<<2 | uint64(edgeSlice(edge))
+	}
+	// Among the permutations of the corners reachable by applying half turns before this
+	// cube, pick the smallest one in lexicographic order.
+	var best, perm [8]Corner
+	for i, h := range halfTurnCorners() {
+		for j, corner := range c.Cp {
+			perm[j] = h[corner]
+		}
+		if i == 0 || lessCorners(perm, best) {
+			best = perm
+		}
+	}
+	for _, corner := range best {
+		id = id<<3 | uint64(corner)
+	}
+	return id
+}
+
+// Build a function returning the permutation of the edges of a slice, from 0 to 23,
+// for cubes whose edges are all in their slice.
+func slicePermutation(positions ...Edge) func(c *CubieCube) int {
+	return func(c *CubieCube) int {
+		perm := [4]int{}
+		for i, position := range positions {
+			for j, edge := range positions {
+				if c.Ep[position] == edge {
+					perm[i] = j
+				}
+			}
+		}
+		return permutationRank(perm[:])
+	}
+}
+
+// Slice an edge belongs to: 0 for the M slice, 1 for the S slice, 2 for the E (UD) slice.
+func edgeSlice(edge Edge) int {
+	switch edge {
+	case UF, UB, DF, DB:
+		return 0
+	case UR, UL, DR, DL:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Return `true` if `a` comes before `b` in lexicographic order.
+func lessCorners(a, b [8]Corner) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+var halfTurnCornersOnce sync.Once
+var halfTurnCornersList [][8]Corner
+var halfTurnCornersIndices map[[8]Corner]int
+
+// The 96 permutations of the corners reachable with half turns.
+func halfTurnCorners() [][8]Corner {
+	halfTurnCornersOnce.Do(func() {
+		halfTurnCornersIndices = map[[8]Corner]int{}
+		solved := NewSolvedCubieCube()
+		found := map[[8]Corner]bool{solved.Cp: true}
+		queue := []CubieCube{solved}
+		for len(queue) > 0 {
+			c := queue[0]
+			queue = queue[1:]
+			halfTurnCornersIndices[c.Cp] = len(halfTurnCornersList)
+			halfTurnCornersList = append(halfTurnCornersList, c.Cp)
+			for _, move := range HalfTurns {
+				next := c.Multiply(cubieMoves[move])
+				if !found[next.Cp] {
+					found[next.Cp] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+	})
+	return halfTurnCornersList
+}
+
+// Index of the permutation of the corners among those reachable with half turns,
+// from 0 to 95.
+func halfTurnCornersIndex(c *CubieCube) int {
+	halfTurnCorners()
+	return halfTurnCornersIndices[c.Cp]
+}
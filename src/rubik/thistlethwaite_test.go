@@ -0,0 +1,105 @@
+package rubik
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSolveThistlethwaite(t *testing.T) {
+	s := NewScrambler(1)
+	for i := 0; i < 20; i++ {
+		c := s.RandomCubieCube()
+		cube := c.ToFacelets()
+		solution, err := SolveThistlethwaite(cube)
+		if err != nil {
+			t.Fatalf("Error while solving %s: %s", cube, err)
+		}
+		if len(solution.Algorithm()) > 45 || !solution.Algorithm().Apply(cube).IsSolved() {
+			t.Errorf("Wrong solution for %s\nGot  %s", cube, solution)
+		}
+	}
+}
+
+func TestSolveThistlethwaitePhases(t *testing.T) {
+	solved := NewSolvedCubieCube()
+	// Return `true` if the cube belongs to the subgroup reached after each phase
+	subgroups := []func(c *CubieCube) bool{
+		func(c *CubieCube) bool { return flipCoord.get(c) == 0 },
+		func(c *CubieCube) bool { return twistCoord.get(c) == 0 && sliceCoord.get(c) == 0 },
+		func(c *CubieCube) bool { return halfTurnsId(c) == halfTurnsId(&solved) },
+		func(c *CubieCube) bool { return c.IsSolved() },
+	}
+	s := NewScrambler(2)
+	for i := 0; i < 20; i++ {
+		c := s.RandomCubieCube()
+		solution, _ := SolveThistlethwaite(c.ToFacelets())
+		for j, phase := range solution {
+			for _, move := range phase {
+				if !containsMove(thistlethwaitePhases[j].moves, move) {
+					t.Errorf("Move %s should not be used in phase %d: %s", move, j+1, solution)
+				}
+				c = c.Multiply(cubieMoves[move])
+			}
+			if !subgroups[j](&c) {
+				t.Errorf("Cube should be in G%d after phase %d: %s", j+1, j+1, solution)
+			}
+		}
+	}
+}
+
+func TestSolveThistlethwaiteShort(t *testing.T) {
+	testCases := []struct {
+		Scramble string
+		Solution string
+	}{
+		{"", "() () () ()"},
+		{"U2", "() () () (U2)"},
+		{"U", "() () (U) (U2)"},
+		{"F", "(F) () () (F2)"},
+	}
+	for _, tc := range testCases {
+		cube := MustParseAlgorithm(tc.Scramble).Apply(NewSolvedCube())
+		solution, err := SolveThistlethwaite(cube)
+		if err != nil || solution.String() != tc.Solution {
+			t.Errorf("Wrong solution for %q\nGot  %s %v\nWant %s", tc.Scramble, solution, err, tc.Solution)
+		}
+	}
+}
+
+func TestSolveThistlethwaiteInvalid(t *testing.T) {
+	cube := MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy")
+	if _, err := SolveThistlethwaite(cube); !errors.Is(err, ErrInvalidCube) {
+		t.Errorf("Invalid cube should not be solved.\nGot  %v\nWant %s", err, ErrInvalidCube)
+	}
+}
+
+func TestThistlethwaiteTables(t *testing.T) {
+	// Maximum number of moves of each phase
+	want := []int{7, 10, 13, 15}
+	s := NewScrambler(3)
+	for j, phase := range thistlethwaitePhases {
+		longest := 0
+		for i := 0; i < 200; i++ {
+			c := s.RandomCubieCube()
+			for _, previous := range thistlethwaitePhases[:j] {
+				_, c = previous.solve(c)
+			}
+			if d := phase.table.distance(&c); d > longest {
+				longest = d
+			}
+		}
+		if longest > want[j] {
+			t.Errorf("Phase %d should take at most %d moves\nGot  %d", j+1, want[j], longest)
+		}
+	}
+}
+
+// Return `true` if the move is in the list.
+func containsMove(moves []Move, move Move) bool {
+	for _, m := range moves {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package rubik
+
+import (
+	"fmt"
+)
+
+// Validation of a Cube, making sure it can be reached from a solved cube.
+//
+// Colors are not required to follow the proposed scheme: the color of each center
+// tells which face the other facelets of the same color belong to.
+
+// Rule broken by an invalid cube.
+type ValidationRule int
+
+const (
+	INVALID_COLOR_COUNT ValidationRule = iota
+	INVALID_CENTERS
+	INVALID_EDGE
+	INVALID_CORNER
+	INVALID_TWIST
+	INVALID_FLIP
+	INVALID_PARITY
+	INVALID_UNKNOWN
+)
+
+// Human readable description of the rule.
+func (rule ValidationRule) String() string {
+	switch rule {
+	case INVALID_COLOR_COUNT:
+		return "each of the 6 colors must appear exactly 9 times"
+	case INVALID_CENTERS:
+		return "centers must have distinct colors"
+	case INVALID_EDGE:
+		return "edge is not a real or unique piece"
+	case INVALID_CORNER:
+		return "corner is not a real or unique piece"
+	case INVALID_TWIST:
+		return "corner twists must add up to a multiple of 3"
+	case INVALID_FLIP:
+		return "edge flips must add up to a multiple of 2"
+	case INVALID_PARITY:
+		return "corner and edge permutations must have the same parity"
+	case INVALID_UNKNOWN:
+		return "facelets must all be known"
+	default:
+		return fmt.Sprintf("rule %d", int(rule))
+	}
+}
+
+// Error describing why a cube is invalid, and which facelets are involved.
+type ValidationError struct {
+	Rule    ValidationRule
+	Indices []int
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s (facelets %v)", ErrInvalidCube, e.Rule, e.Indices)
+}
+
+// A *ValidationError is an ErrInvalidCube.
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidCube
+}
+
+// Check that this cube can be reached from a solved cube.
+//
+// Return `nil` if the cube is valid, or a *ValidationError telling the first broken rule.
+// Rules are checked in this order: known facelets, color counts, distinct centers,
+// real edges, real corners, corner twist, edge flip and permutation parity.
+func (cube Cube) Validate() error {
+	if indices := cube.unknownFacelets(); len(indices) > 0 {
+		return &ValidationError{INVALID_UNKNOWN, indices}
+	}
+	if err := cube.validateColorCount(); err != nil {
+		return err
+	}
+	faces, err := cube.facesByColor()
+	if err != nil {
+		return err
+	}
+	p, err := cube.locatePieces(faces)
+	if err != nil {
+		return err
+	}
+
+	twist := 0
+	for _, co := range p.Co {
+		twist += co
+	}
+	if twist%3 != 0 {
+		indices := []int{}
+		for i, co := range p.Co {
+			if co != 0 {
+				indices = append(indices, cornerFacelets[i][:]...)
+			}
+		}
+		return &ValidationError{INVALID_TWIST, indices}
+	}
+
+	flip := 0
+	for _, eo := range p.Eo {
+		flip += eo
+	}
+	if flip%2 != 0 {
+		indices := []int{}
+		for i, eo := range p.Eo {
+			if eo != 0 {
+				indices = append(indices, edgeFacelets[i][:]...)
+			}
+		}
+		return &ValidationError{INVALID_FLIP, indices}
+	}
+
+	cornerPerm := make([]int, len(p.Cp))
+	for i, c := range p.Cp {
+		cornerPerm[i] = int(c)
+	}
+	edgePerm := make([]int, len(p.Ep))
+	for i, e := range p.Ep {
+		edgePerm[i] = int(e)
+	}
+	if parity(cornerPerm) != parity(edgePerm) {
+		indices := []int{}
+		for i, c := range p.Cp {
+			if int(c) != i {
+				indices = append(indices, cornerFacelets[i][:]...)
+			}
+		}
+		for i, e := range p.Ep {
+			if int(e) != i {
+				indices = append(indices, edgeFacelets[i][:]...)
+			}
+		}
+		return &ValidationError{INVALID_PARITY, indices}
+	}
+
+	return nil
+}
+
+// Indices of the facelets whose color is unknown.
+func (cube Cube) unknownFacelets() []int {
+	indices := []int{}
+	for i, color := range cube {
+		if color == UNKNOWN {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// Check that there are 6 colors, each of them appearing 9 times.
+func (cube Cube) validateColorCount() error {
+	counts := [256]int{}
+	colors := []byte{}
+	for _, c := range cube {
+		if counts[c] == 0 {
+			colors = append(colors, c)
+		}
+		counts[c]++
+	}
+	for _, color := range colors {
+		if counts[color] != 9 || len(colors) != 6 {
+			indices := []int{}
+			for i, c := range cube {
+				if c == color {
+					indices = append(indices, i)
+				}
+			}
+			return &ValidationError{INVALID_COLOR_COUNT, indices}
+		}
+	}
+	return nil
+}
+
+// Map each color to its face, as given by the centers.
+func (cube Cube) facesByColor() (map[byte]int, error) {
+	faces := map[byte]int{}
+	for face, i := range centerFacelets {
+		if other, found := faces[cube[i]]; found {
+			return nil, &ValidationError{INVALID_CENTERS, []int{centerFacelets[other], i}}
+		}
+		faces[cube[i]] = face
+	}
+	return faces, nil
+}
+
+// Find which piece is located at each position, and how it is oriented.
+func (cube Cube) locatePieces(faces map[byte]int) (*CubieCube, error) {
+	p := &CubieCube{}
+
+	foundEdges := map[Edge]int{}
+	for i, facelets := range edgeFacelets {
+		var found bool
+		p.Ep[i], p.Eo[i], found = findEdge([2]int{faces[cube[facelets[0]]], faces[cube[facelets[1]]]})
+		if !found {
+			return nil, &ValidationError{INVALID_EDGE, facelets[:]}
+		}
+		if other, dup := foundEdges[p.Ep[i]]; dup {
+			indices := append(edgeFacelets[other][:], facelets[:]...)
+			return nil, &ValidationError{INVALID_EDGE, indices}
+		}
+		foundEdges[p.Ep[i]] = i
+	}
+
+	foundCorners := map[Corner]int{}
+	for i, facelets := range cornerFacelets {
+		f := [3]int{}
+		for j, facelet := range facelets {
+			f[j] = faces[cube[facelet]]
+		}
+		var found bool
+		p.Cp[i], p.Co[i], found = findCorner(f)
+		if !found {
+			return nil, &ValidationError{INVALID_CORNER, facelets[:]}
+		}
+		if other, dup := foundCorners[p.Cp[i]]; dup {
+			indices := append(cornerFacelets[other][:], facelets[:]...)
+			return nil, &ValidationError{INVALID_CORNER, indices}
+		}
+		foundCorners[p.Cp[i]] = i
+	}
+
+	return p, nil
+}
+
+// Find the edge whose facelets belong to the given faces, and its flip.
+// Return `false` if no edge has these faces.
+func findEdge(f [2]int) (Edge, int, bool) {
+	for e := UR; e <= BR; e++ {
+		home := edgeFaces(e)
+		if home[0] == f[0] && home[1] == f[1] {
+			return e, 0, true
+		} else if home[0] == f[1] && home[1] == f[0] {
+			return e, 1, true
+		}
+	}
+	return 0, 0, false
+}
+
+// Find the corner whose facelets belong to the given faces, and its twist.
+// Return `false` if no corner has these faces, in this order.
+func findCorner(f [3]int) (Corner, int, bool) {
+	for c := URF; c <= DRB; c++ {
+		home := cornerFaces(c)
+		for ori := 0; ori < 3; ori++ {
+			if f[ori] == home[0] && f[(ori+1)%3] == home[1] && f[(ori+2)%3] == home[2] {
+				return c, ori, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// Return 0 if the given permutation is even, 1 if it is odd.
+func parity(perm []int) int {
+	p := 0
+	for i := 0; i < len(perm); i++ {
+		for j := i + 1; j < len(perm); j++ {
+			if perm[i] > perm[j] {
+				p++
+			}
+		}
+	}
+	return p % 2
+}
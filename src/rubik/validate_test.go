@@ -0,0 +1,107 @@
+package rubik
+
+import (
+	"testing"
+)
+
+func TestValidateValid(t *testing.T) {
+	cubes := []Cube{
+		NewSolvedCube(),
+		NewSolvedCube().F().R().R().U().L(),
+		MustParseCube("bwwbwwyyr orwogbygb gbbrrwrrw ooygbygbr oogoogyyb rrwgywgyo"),
+		MustParseCube("sssssssss qqqqqqqqq ppppppppp nnnnnnnnn mmmmmmmmm lllllllll").B().Dc().L(),
+	}
+	for _, cube := range cubes {
+		if err := cube.Validate(); err != nil {
+			t.Errorf("Cube should be valid: %s\nGot  %s", cube, err)
+		}
+	}
+}
+
+func TestValidateAllMoves(t *testing.T) {
+	cube := NewSolvedCube()
+	for i := 0; i < 100; i++ {
+		cube = cube.MustTurn(Moves[(i*7)%len(Moves)])
+		if err := cube.Validate(); err != nil {
+			t.Fatalf("Cube should be valid after %d moves: %s\nGot  %s", i+1, cube, err)
+		}
+	}
+}
+
+func TestValidateRandomMoves(t *testing.T) {
+	s := NewScrambler(1)
+	for i := 0; i < 100; i++ {
+		alg, cube, _ := s.RandomMoves(20)
+		if err := cube.Validate(); err != nil {
+			t.Fatalf("Cube should be valid after %s: %s\nGot  %s", alg, cube, err)
+		}
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	testCases := []struct {
+		Cube    Cube
+		Rule    ValidationRule
+		Indices []int
+	}{
+		// Typo: one 'w' replaced by an 'x'
+		{MustParseCube("wwwwxwwww ggggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy"), INVALID_COLOR_COUNT, []int{0, 1, 2, 3, 5, 6, 7, 8}},
+		// Two white centers
+		{MustParseCube("gwwwwwwww ggggwgggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy"), INVALID_CENTERS, []int{4, 13}},
+		// UF edge with two green stickers
+		{MustParseCube("wwwwwwwgw ggggggggw rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy"), INVALID_EDGE, []int{7, 10}},
+		// White/yellow edge does not exist
+		{MustParseCube("wwwwwwwww ggggggggg ryrrrrrrr bbbbbbbbb ooooooooo yryyyyyyy"), INVALID_EDGE, []int{5, 19}},
+		// URF corner with two green stickers
+		{MustParseCube("wwwwwwwwg wgggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy"), INVALID_CORNER, []int{8, 18, 11}},
+		// URF corner twisted
+		{MustParseCube("wwwwwwwwg ggrgggggg wrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy"), INVALID_TWIST, []int{8, 18, 11}},
+		// UF edge flipped
+		{MustParseCube("wwwwwwwgw gwggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy"), INVALID_FLIP, []int{7, 10}},
+		// UR and UF edges swapped
+		{MustParseCube("wwwwwwwww grggggggg rgrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy"), INVALID_PARITY, []int{5, 19, 7, 10}},
+		// Unknown UF edge
+		{MustParseCube("wwwwwww?w ??ggggggg rrrrrrrrr bbbbbbbbb ooooooooo yyyyyyyyy"), INVALID_UNKNOWN, []int{7, 9, 10}},
+	}
+
+	for _, tc := range testCases {
+		err := tc.Cube.Validate()
+		if err == nil {
+			t.Errorf("Cube should be invalid: %s", tc.Cube)
+			continue
+		}
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			t.Errorf("Unexpected error type for %s: %T", tc.Cube, err)
+			continue
+		}
+		if verr.Rule != tc.Rule {
+			t.Errorf("Wrong rule for %s\nGot  %s\nWant %s", tc.Cube, verr.Rule, tc.Rule)
+		}
+		if tc.Indices != nil && !equalInts(verr.Indices, tc.Indices) {
+			t.Errorf("Wrong facelets for %s\nGot  %v\nWant %v", tc.Cube, verr.Indices, tc.Indices)
+		}
+	}
+}
+
+func TestValidateMirroredCorner(t *testing.T) {
+	// Exchange the red and green stickers of the URF corner
+	cube := NewSolvedCube()
+	cube[18], cube[11] = cube[11], cube[18]
+	err := cube.Validate()
+	if verr, ok := err.(*ValidationError); !ok || verr.Rule != INVALID_CORNER {
+		t.Errorf("Mirrored corner should be invalid: %s\nGot  %v", cube, err)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}